@@ -4,87 +4,191 @@ Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
 package cmd
 
 import (
-	"bytes"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 
 	"github.com/Kei-K23/git-go/internal/utils"
 	"github.com/spf13/cobra"
 )
 
+var addAll bool   // Stage changes of every file in working tree (-A)
+var addForce bool // Allow adding ignored files
+
 // addCmd represents the add command
 var addCmd = &cobra.Command{
-	Use:   "add [file]",
+	Use:   "add [<pathspec>...]",
 	Short: "Add file contents to the index",
 	Run: func(cmd *cobra.Command, args []string) {
-		for _, file := range args {
-			if _, err := os.Stat(file); os.IsNotExist(err) {
-				// TODO:: Check whether should i use log.Fatalln or fmt.println
-				log.Fatalf("File '%s' does not exist", file)
-			} else {
-				// Check is file is already added or if file content is modified
-				isModified := utils.IsFileModified(file)
-				if !isModified {
-					fmt.Printf("'%s' file is already in staging area\n", file)
-					os.Exit(0)
-				}
+		if len(args) == 0 && !addAll {
+			fmt.Println("Nothing specified, nothing added.")
+			fmt.Println("hint: Maybe you wanted to say 'git-go add .'?")
+			return
+		}
 
-				// Read file content
-				fileContentBytes, err := os.ReadFile(file)
-				if err != nil {
-					log.Fatalf("Cannot read the content of file '%s'", file)
-				}
+		// Pathspec is relative to folder where command is started, index stores path relative to working tree root
+		var pathspecs []string
+		for _, arg := range args {
+			pathspec, err := utils.ToRepoPath(arg)
+			if err != nil {
+				log.Fatalf("fatal: %v", err)
+			}
+			pathspecs = append(pathspecs, pathspec)
+		}
+		if len(pathspecs) == 0 {
+			pathspecs = append(pathspecs, ".") // -A without pathspec means whole working tree
+		}
 
-				// Generate the hash value from file content
-				hashValue, err := utils.HandFileContent(fileContentBytes)
-				if err != nil {
-					log.Fatalf("Cannot read the content of file '%s'", file)
+		entries, err := utils.ReadIndexFile()
+		if err != nil {
+			log.Fatalln("Error while reading index file content")
+		}
+
+		workingFiles, err := utils.ListWorkingFiles()
+		if err != nil {
+			log.Fatalln("Error while reading working directory:", err)
+		}
+
+		// Files to stage are working files (ignored ones are left out) and tracked files matching any pathspec
+		matched := make([]bool, len(pathspecs))
+		toStage := make(map[string]bool)
+		matchAny := func(path string) bool {
+			isMatched := false
+			for i, pathspec := range pathspecs {
+				if utils.MatchPathspec(pathspec, path) {
+					matched[i] = true
+					isMatched = true
 				}
+			}
+			return isMatched
+		}
+		for _, file := range workingFiles {
+			if matchAny(file) {
+				toStage[file] = true
+			}
+		}
 
-				// Create file and folder to store compressed blob content
-				os.Mkdir(fmt.Sprintf(".git-go/objects/%s", hashValue[:2]), 0755)
-				blogFilePath := fmt.Sprintf(".git-go/objects/%s/%s", hashValue[:2], hashValue[2:])
+		// Tracked files that are removed from working tree are removed from index
+		removed := make(map[string]bool)
+		for _, entry := range entries {
+			if !matchAny(entry.Path) {
+				continue
+			}
+			if _, err := os.Lstat(entry.Path); err != nil {
+				removed[entry.Path] = true
+			} else {
+				toStage[entry.Path] = true // Tracked file is staged even when it is ignored
+			}
+		}
 
-				blobFile, err := os.Create(blogFilePath)
+		// Ignored file or folder given by its path is only added with -f
+		ignoreMatcher := utils.NewIgnoreMatcher()
+		var ignoredPaths []string
+		for i, pathspec := range pathspecs {
+			if matched[i] || utils.IsGlobPathspec(pathspec) {
+				continue
+			}
+			fileInfo, err := os.Lstat(pathspec)
+			if err != nil {
+				fmt.Printf("fatal: pathspec '%s' did not match any files\n", pathspec)
+				os.Exit(1)
+			}
+			if !ignoreMatcher.IsIgnored(pathspec, fileInfo.IsDir()) {
+				continue // Empty folder has nothing to add
+			}
+			if !addForce {
+				ignoredPaths = append(ignoredPaths, pathspec)
+				continue
+			}
+			if !fileInfo.IsDir() {
+				toStage[pathspec] = true
+			}
+		}
+		for i, pathspec := range pathspecs {
+			if !matched[i] && utils.IsGlobPathspec(pathspec) {
+				fmt.Printf("fatal: pathspec '%s' did not match any files\n", pathspec)
+				os.Exit(1)
+			}
+		}
 
-				if err != nil {
-					log.Fatalln("Error when creating blob file in .git-go/objects")
-				}
-				defer blobFile.Close()
-				// Create new zlib compress writer
-				var compressBuf bytes.Buffer
+		var files []string
+		for file := range toStage {
+			files = append(files, file)
+		}
+		sort.Strings(files)
 
-				err = utils.CompressContent(&compressBuf, fileContentBytes)
+		entriesByPath := utils.EntriesToMap(entries)
+		for _, file := range files {
+			fileInfo, err := os.Lstat(file)
+			if err != nil {
+				log.Fatalf("Cannot read the stat data of file '%s'", file)
+			}
 
+			// Check is file is already added or if file content is modified
+			if entry, ok := entriesByPath[file]; ok && entry.Stage() == 0 {
+				isModified, err := utils.IsEntryModified(entry, fileInfo)
 				if err != nil {
-					log.Fatalln("Error when compressing file content")
+					log.Fatalf("Cannot read the content of file '%s'", file)
+				}
+				if !isModified {
+					if len(pathspecs) == 1 && pathspecs[0] == file {
+						fmt.Printf("'%s' file is already in staging area\n", file)
+					}
+					continue
 				}
+			}
 
-				// Add compressed file content call blob to objects file
-				blobFile.Write(compressBuf.Bytes())
+			// Read file content
+			fileContentBytes, err := utils.ReadWorkingFile(file)
+			if err != nil {
+				log.Fatalf("Cannot read the content of file '%s'", file)
+			}
 
-				// Add hash value and file name to index file inside .git-go (meaning add file to staging area)
-				// example content of index file data without compression 100644 76c7b5bdd3d61bb2657e00b06870f4553294d2a9 README.md
-				entries, err := utils.ReadIndexFile()
-				if err != nil {
-					log.Fatalln("Error while reading index file content")
-				}
+			// Store file content as blob object (blob object is compressed with object header)
+			hashValue, err := utils.WriteObject(utils.BlobObject, fileContentBytes)
+			if err != nil {
+				log.Fatalln("Error when writing blob object in .git-go/objects")
+			}
 
-				updatedEntires := utils.UpdateIndexFileHashValue(entries, file, hashValue)
+			// Add hash value, file name and file stat data to index entries (meaning add file to staging area)
+			newEntry, err := utils.NewIndexEntry(file, hashValue)
+			if err != nil {
+				log.Fatalf("Cannot read the stat data of file '%s'", file)
+			}
+			entries = utils.UpdateIndexEntry(entries, newEntry)
 
-				err = utils.WriteIndexFile(updatedEntires)
+			fmt.Printf("Stored object as : %s\n", utils.ObjectPath(hashValue))
+		}
 
-				if err != nil {
-					log.Fatalln("Error while adding entry to index file")
+		if len(removed) > 0 {
+			var remaining []utils.IndexEntry
+			for _, entry := range entries {
+				if !removed[entry.Path] {
+					remaining = append(remaining, entry)
 				}
+			}
+			entries = remaining
+		}
+
+		err = utils.WriteIndexFile(entries)
+		if err != nil {
+			log.Fatalln("Error while adding entry to index file")
+		}
 
-				fmt.Printf("Stored object as : %s\n", blogFilePath)
+		if len(ignoredPaths) > 0 {
+			fmt.Println("The following paths are ignored by one of your .gitignore files:")
+			for _, path := range ignoredPaths {
+				fmt.Println(path)
 			}
+			fmt.Println("hint: Use -f if you really want to add them.")
+			os.Exit(1)
 		}
 	},
 }
 
 func init() {
+	addCmd.Flags().BoolVarP(&addAll, "all", "A", false, "Add changes from all tracked and untracked files")
+	addCmd.Flags().BoolVarP(&addForce, "force", "f", false, "Allow adding otherwise ignored files")
 	rootCmd.AddCommand(addCmd)
 }
@@ -22,7 +22,12 @@ var branchCmd = &cobra.Command{
 
 		// If delete branch flag exist, then perform branch deletion
 		if branchName != "" {
-			err := os.Remove(".git-go/refs/heads/" + branchName)
+			// Name is joined onto refs/heads, "../../HEAD" would delete HEAD file
+			if err := utils.ValidateRefName("refs/heads/" + branchName); err != nil {
+				fmt.Printf("fatal: '%s' is not a valid branch name\n", branchName)
+				os.Exit(1)
+			}
+			err := utils.DeleteRef("refs/heads/" + branchName)
 			if err != nil {
 				log.Fatalf("Error while deletion of '%s' branch\n", branchName)
 			}
@@ -32,8 +37,13 @@ var branchCmd = &cobra.Command{
 
 		// Create new branch is branch name is provided
 		if len(args) > 0 {
+			if err := utils.ValidateRefName("refs/heads/" + args[0]); err != nil {
+				fmt.Printf("fatal: '%s' is not a valid branch name\n", args[0])
+				os.Exit(1)
+			}
+
 			// Check that branch name already exist
-			dirEntries, err := os.ReadDir(".git-go/refs/heads")
+			dirEntries, err := os.ReadDir(utils.GitPath("refs", "heads"))
 			if err != nil {
 				log.Fatalln("Error while reading heads dir")
 			}
@@ -45,23 +55,19 @@ var branchCmd = &cobra.Command{
 				}
 			}
 
-			path := ".git-go/refs/heads/" + args[0]
-			file, err := os.Create(path)
-			if err != nil {
-				log.Fatalln("Error while creating new branch")
-			}
-			defer file.Close()
-
 			// Get current commit hash value and added to new created branch
 			currentCommit := utils.GetCurrentCommit()
 
-			file.Write([]byte(currentCommit))
+			err = utils.CreateBranch(args[0], currentCommit, "branch: Created from HEAD")
+			if err != nil {
+				log.Fatalln("Error while creating new branch")
+			}
 			fmt.Printf("New branch call '%s' is created\n", args[0])
 			os.Exit(0)
 		}
 
 		// No branch name is provided to create, then show all branch name
-		dirEntries, err := os.ReadDir(".git-go/refs/heads")
+		dirEntries, err := os.ReadDir(utils.GitPath("refs", "heads"))
 		if err != nil {
 			log.Fatalln("Error while reading heads dir")
 		}
@@ -0,0 +1,113 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var catFileType bool   // variable to store -t flag to show object type
+var catFileSize bool   // variable to store -s flag to show object size
+var catFilePretty bool // variable to store -p flag to pretty-print object content
+var catFileExists bool // variable to store -e flag to check object exists
+
+// catFileCmd represents the cat-file command
+var catFileCmd = &cobra.Command{
+	Use:   "cat-file (-t | -s | -p | -e | <type>) <object>",
+	Short: "Provide content, type or size information for repository objects",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Without flags, first argument is the expected object type (e.g cat-file blob <object>)
+		expectedType := ""
+		objectName := args[0]
+		if len(args) == 2 {
+			expectedType = args[0]
+			objectName = args[1]
+		}
+
+		selectedFlags := 0
+		for _, flag := range []bool{catFileType, catFileSize, catFilePretty, catFileExists} {
+			if flag {
+				selectedFlags++
+			}
+		}
+		if (expectedType == "" && selectedFlags != 1) || (expectedType != "" && selectedFlags != 0) {
+			fmt.Println("usage: git-go cat-file (-t | -s | -p | -e | <type>) <object>")
+			os.Exit(1)
+		}
+
+		hashValue, err := utils.ResolveObjectName(objectName)
+		if err != nil {
+			if catFileExists {
+				os.Exit(1) // -e only reports result with exit status
+			}
+			fmt.Printf("fatal: Not a valid object name %s\n", objectName)
+			os.Exit(1)
+		}
+
+		objType, content, err := utils.ReadObject(hashValue)
+		if err != nil {
+			if catFileExists {
+				os.Exit(1)
+			}
+			fmt.Printf("fatal: Cannot read object %s: %v\n", hashValue, err)
+			os.Exit(1)
+		}
+
+		switch {
+		case catFileExists:
+			os.Exit(0)
+		case catFileType:
+			fmt.Println(objType)
+		case catFileSize:
+			fmt.Println(len(content))
+		case catFilePretty:
+			prettyPrintObject(objType, content)
+		default:
+			if objType != expectedType {
+				fmt.Printf("fatal: git-go cat-file %s: object is a %s, not a %s\n", objectName, objType, expectedType)
+				os.Exit(1)
+			}
+			os.Stdout.Write(content)
+		}
+	},
+}
+
+// Print object content in human readable format depending on object type
+func prettyPrintObject(objType string, content []byte) {
+	if objType != utils.TreeObject {
+		// Blob, commit and tag content is already readable
+		os.Stdout.Write(content)
+		return
+	}
+
+	treeEntries, err := utils.ParseTree(content)
+	if err != nil {
+		fmt.Printf("fatal: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Tree entry is printed as "<mode> <type> <hash>\t<name>" (e.g 100644 blob 3b18e5...\tREADME.md)
+	for _, treeEntry := range treeEntries {
+		entryType := utils.BlobObject
+		if treeEntry.IsTree() {
+			entryType = utils.TreeObject
+		} else if treeEntry.Mode == "160000" {
+			entryType = utils.CommitObject
+		}
+		fmt.Printf("%06s %s %s\t%s\n", treeEntry.Mode, entryType, treeEntry.Hash, treeEntry.Name)
+	}
+}
+
+func init() {
+	catFileCmd.Flags().BoolVarP(&catFileType, "type", "t", false, "Show object type")
+	catFileCmd.Flags().BoolVarP(&catFileSize, "size", "s", false, "Show object size")
+	catFileCmd.Flags().BoolVarP(&catFilePretty, "pretty", "p", false, "Pretty-print object content")
+	catFileCmd.Flags().BoolVarP(&catFileExists, "exists", "e", false, "Exit with zero status if object exists and is valid")
+	rootCmd.AddCommand(catFileCmd)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var checkoutForce bool // variable to store force flag to discard local changes
+
+// checkoutCmd represents the checkout command
+var checkoutCmd = &cobra.Command{
+	Use:   "checkout <branch|commit>",
+	Short: "Switch branches or restore working tree files",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		// Checkout branch and make HEAD point to it
+		if utils.BranchExists(target) {
+			switchToBranch(target, checkoutForce)
+			return
+		}
+
+		// Otherwise checkout commit directly (detached HEAD)
+		commitHash, err := utils.ResolveRevision(target)
+		if err != nil {
+			fmt.Printf("error: pathspec '%s' did not match any branch or commit\n", target)
+			os.Exit(1)
+		}
+
+		err = utils.CheckoutCommit(commitHash, checkoutForce)
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+			os.Exit(1)
+		}
+
+		err = utils.SetDetachedHead(commitHash, checkoutMessage(target))
+		if err != nil {
+			log.Fatalln("Error while updating HEAD file:", err)
+		}
+
+		fmt.Printf("HEAD is now at %s (detached HEAD)\n", commitHash[:7])
+	},
+}
+
+// Update working tree and index to branch commit, then make HEAD point to the branch
+func switchToBranch(branch string, force bool) {
+	if utils.GerCurrentBranch() == branch {
+		fmt.Printf("Already on '%s'\n", branch)
+		return
+	}
+
+	// Branch without any commit doesn't have snapshot to checkout
+	commitHash := utils.GetBranchCommit(branch)
+	if commitHash != "" {
+		err := utils.CheckoutCommit(commitHash, force)
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	err := utils.SetHeadToBranch(branch, checkoutMessage(branch))
+	if err != nil {
+		log.Fatalln("Error while updating HEAD file:", err)
+	}
+
+	fmt.Printf("Switched to branch '%s'\n", branch)
+}
+
+// Reflog message of HEAD moving from current branch (or detached commit) to target
+func checkoutMessage(target string) string {
+	from := utils.GerCurrentBranch()
+	if from == "" {
+		from = utils.GetCurrentCommit()
+	}
+	return fmt.Sprintf("checkout: moving from %s to %s", from, target)
+}
+
+func init() {
+	checkoutCmd.Flags().BoolVarP(&checkoutForce, "force", "f", false, "Throw away local changes")
+	rootCmd.AddCommand(checkoutCmd)
+}
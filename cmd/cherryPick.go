@@ -0,0 +1,340 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// Folder inside repository folder that keeps remaining commits of cherry-pick or revert in progress
+const sequencerStateDir = "sequencer"
+
+var sequencerContinue bool // Continue after conflicts are resolved
+var sequencerAbort bool    // Go back to the state before cherry-pick or revert started
+var sequencerSkip bool     // Drop the commit that stopped and continue
+
+// cherryPickCmd represents the cherry-pick command
+var cherryPickCmd = &cobra.Command{
+	Use:   "cherry-pick <commit>... | --continue | --abort | --skip",
+	Short: "Apply the changes introduced by some existing commits",
+	Run: func(cmd *cobra.Command, args []string) {
+		runSequencerCommand("cherry-pick", args)
+	},
+}
+
+// Get name of state file that records commit being applied when it stopped (e.g CHERRY_PICK_HEAD)
+func sequencerHeadFile(action string) string {
+	if action == "revert" {
+		return "REVERT_HEAD"
+	}
+	return "CHERRY_PICK_HEAD"
+}
+
+// Handle cherry-pick or revert command line, action is "cherry-pick" or "revert"
+func runSequencerCommand(action string, args []string) {
+	actionCount := 0
+	for _, isSet := range []bool{sequencerContinue, sequencerAbort, sequencerSkip} {
+		if isSet {
+			actionCount++
+		}
+	}
+	if actionCount > 1 || (actionCount == 1 && len(args) > 0) {
+		fmt.Println("fatal: --continue, --abort and --skip can't be combined with other options")
+		os.Exit(1)
+	}
+
+	runningAction, inProgress := utils.ReadStateFile(sequencerStateDir + "/action")
+	runningAction = strings.TrimSpace(runningAction)
+	if actionCount == 1 && (!inProgress || runningAction != action) {
+		fmt.Printf("error: no %s in progress\n", action)
+		os.Exit(1)
+	}
+
+	switch {
+	case sequencerContinue:
+		continueSequencer(action)
+	case sequencerAbort:
+		origHead, _ := utils.ReadStateFile(sequencerStateDir + "/head")
+		origHead = strings.TrimSpace(origHead)
+		err := utils.CheckoutCommit(origHead, true)
+		if err != nil {
+			log.Fatalln("Error while resetting working tree:", err)
+		}
+		utils.UpdateCommitHashValue(origHead, action+": abort")
+		endSequencer(action)
+	case sequencerSkip:
+		err := utils.CheckoutCommit(utils.GetCurrentCommit(), true)
+		if err != nil {
+			log.Fatalln("Error while resetting working tree:", err)
+		}
+		// Commit that failed before it was applied is still at the head of todo
+		if _, isStopped := utils.ReadStateFile(sequencerHeadFile(action)); !isStopped {
+			content, _ := utils.ReadStateFile(sequencerStateDir + "/todo")
+			if todo := strings.Fields(content); len(todo) > 0 {
+				popSequencerTodo(todo)
+			}
+		}
+		utils.RemoveStateFiles(sequencerHeadFile(action), "MERGE_MSG")
+		runSequencer(action)
+	default:
+		if len(args) == 0 {
+			fmt.Println("fatal: empty commit set passed")
+			os.Exit(1)
+		}
+		if inProgress {
+			fmt.Printf("error: %s is already in progress\n", runningAction)
+			fmt.Printf("hint: try \"git-go %s (--continue | --skip | --abort)\"\n", runningAction)
+			os.Exit(1)
+		}
+		startSequencer(action, args)
+	}
+}
+
+// Resolve commits to apply, "<a>..<b>" is every commit reachable from b but not from a
+// Cherry-pick applies oldest commit first, revert undoes newest commit first (same as Git)
+func resolveSequencerCommits(action string, args []string) []string {
+	var commits []string
+	for _, arg := range args {
+		from, to, isRange := strings.Cut(arg, "..")
+		if !isRange {
+			commitHash, err := utils.ResolveRevision(arg)
+			if err != nil {
+				fmt.Printf("fatal: bad revision '%s'\n", arg)
+				os.Exit(1)
+			}
+			commits = append(commits, commitHash)
+			continue
+		}
+
+		if to == "" {
+			to = "HEAD"
+		}
+		fromHash, err := utils.ResolveRevision(from)
+		if err == nil {
+			var toHash string
+			toHash, err = utils.ResolveRevision(to)
+			if err == nil {
+				var rangeCommits []utils.Commit
+				rangeCommits, err = utils.CommitsBetween(fromHash, toHash)
+				for i := range rangeCommits {
+					if action == "revert" {
+						i = len(rangeCommits) - 1 - i
+					}
+					commits = append(commits, rangeCommits[i].Hash)
+				}
+			}
+		}
+		if err != nil {
+			fmt.Printf("fatal: bad revision '%s'\n", arg)
+			os.Exit(1)
+		}
+	}
+	return commits
+}
+
+// Save commits to apply and start applying them, working tree and index must be clean
+func startSequencer(action string, args []string) {
+	commits := resolveSequencerCommits(action, args)
+	if len(commits) == 0 {
+		fmt.Println("error: empty commit set passed")
+		os.Exit(1)
+	}
+
+	headCommit := utils.GetCurrentCommit()
+	if headCommit == "" {
+		fmt.Println("fatal: HEAD does not point to any commit yet")
+		os.Exit(1)
+	}
+
+	localChanges, err := utils.LocalChanges()
+	if err != nil {
+		log.Fatalln("Error while reading index file:", err)
+	}
+	if len(localChanges) > 0 {
+		fmt.Printf("error: your local changes would be overwritten by %s.\n", action)
+		fmt.Println("hint: commit your changes or stash them to proceed.")
+		fmt.Printf("fatal: %s failed\n", action)
+		os.Exit(1)
+	}
+
+	err = utils.WriteStateFile(sequencerStateDir+"/action", action+"\n")
+	if err == nil {
+		err = utils.WriteStateFile(sequencerStateDir+"/head", headCommit+"\n")
+	}
+	if err == nil {
+		err = utils.WriteStateFile(sequencerStateDir+"/todo", strings.Join(commits, "\n")+"\n")
+	}
+	if err != nil {
+		log.Fatalln("Error while saving sequencer state:", err)
+	}
+
+	runSequencer(action)
+}
+
+// Apply remaining commits one at a time, state is saved before each commit so it can stop at any point
+func runSequencer(action string) {
+	for {
+		content, _ := utils.ReadStateFile(sequencerStateDir + "/todo")
+		todo := strings.Fields(content)
+		if len(todo) == 0 {
+			endSequencer(action)
+			return
+		}
+
+		// Commit stays at the head of todo until it is applied (or stopped with conflicts), so --continue retries it after failure
+		commit, err := utils.ReadCommit(todo[0])
+		if err != nil {
+			log.Fatalln("Error when reading commit object:", err)
+		}
+		if len(commit.Parents) > 1 {
+			fmt.Printf("error: commit %s is a merge but no -m option was given.\n", commit.Hash)
+			fmt.Printf("fatal: %s failed\n", action)
+			os.Exit(1)
+		}
+
+		message := strings.TrimSpace(commit.Message)
+		if action == "revert" {
+			message = fmt.Sprintf("Revert \"%s\"\n\nThis reverts commit %s.", commit.Subject(), commit.Hash)
+		}
+
+		result, err := utils.ApplyCommitChanges(commit, action == "revert")
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+			fmt.Printf("fatal: %s failed\n", action)
+			os.Exit(1)
+		}
+		popSequencerTodo(todo)
+
+		if len(result.Conflicts) > 0 {
+			err = utils.WriteStateFile(sequencerHeadFile(action), commit.Hash+"\n")
+			if err == nil {
+				err = utils.WriteStateFile("MERGE_MSG", message+"\n")
+			}
+			if err != nil {
+				log.Fatalln("Error while saving sequencer state:", err)
+			}
+
+			for _, path := range result.Conflicts {
+				fmt.Printf("CONFLICT (content): Merge conflict in %s\n", path)
+			}
+			verb := "apply"
+			if action == "revert" {
+				verb = "revert"
+			}
+			fmt.Printf("error: could not %s %s... %s\n", verb, commit.Hash[:7], commit.Subject())
+			fmt.Println("hint: After resolving the conflicts, mark them with")
+			fmt.Println("hint: \"git-go add <pathspec>\", then run")
+			fmt.Printf("hint: \"git-go %s --continue\".\n", action)
+			fmt.Printf("hint: You can instead skip this commit with \"git-go %s --skip\".\n", action)
+			fmt.Printf("hint: To abort and get back to the state before \"git-go %s\",\n", action)
+			fmt.Printf("hint: run \"git-go %s --abort\".\n", action)
+			os.Exit(1)
+		}
+
+		commitSequencerStep(action, commit, message)
+	}
+}
+
+// Remove first commit from todo once it is applied
+func popSequencerTodo(todo []string) {
+	err := utils.WriteStateFile(sequencerStateDir+"/todo", strings.Join(todo[1:], "\n")+"\n")
+	if err != nil {
+		log.Fatalln("Error while saving sequencer state:", err)
+	}
+}
+
+// Create commit from index for applied commit, cherry-picked commit keeps its original author
+func commitSequencerStep(action string, commit utils.Commit, message string) {
+	entries, err := utils.ReadIndexFile()
+	if err != nil {
+		log.Fatalln("Error while reading index file")
+	}
+	treeHash, err := utils.WriteTree(entries)
+	if err != nil {
+		log.Fatalln("Error when creating tree object:", err)
+	}
+
+	headCommit := utils.GetCurrentCommit()
+	if utils.GetCommitTreeHash(headCommit) == treeHash {
+		// Keep stopped commit recorded, so status shows it and --skip can drop it
+		utils.WriteStateFile(sequencerHeadFile(action), commit.Hash+"\n")
+		fmt.Printf("The previous %s is now empty, possibly due to conflict resolution.\n", action)
+		fmt.Printf("hint: use \"git-go %s --skip\" to skip this commit\n", action)
+		os.Exit(1)
+	}
+
+	signature, err := utils.GetUserSignature()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	author := signature
+	if action == "cherry-pick" {
+		author = commit.Author
+	}
+
+	newCommit := utils.Commit{Tree: treeHash, Parents: []string{headCommit}, Author: author, Committer: signature, Message: message}
+	hashValue, err := utils.WriteCommit(newCommit)
+	if err != nil {
+		log.Fatalln("Error when creating commit object:", err)
+	}
+	utils.UpdateCommitHashValue(hashValue, action+": "+newCommit.Subject())
+	utils.RemoveStateFiles(sequencerHeadFile(action), "MERGE_MSG")
+
+	currentBranch := utils.GerCurrentBranch()
+	if currentBranch == "" {
+		currentBranch = "detached HEAD"
+	}
+	fmt.Printf("[%s %s] %s\n", currentBranch, hashValue[:7], newCommit.Subject())
+}
+
+// Commit resolved conflicts of stopped commit (unless it is already committed) and apply remaining commits
+func continueSequencer(action string) {
+	entries, err := utils.ReadIndexFile()
+	if err != nil {
+		log.Fatalln("Error while reading index file")
+	}
+	if unmergedPaths := utils.UnmergedPaths(entries); len(unmergedPaths) > 0 {
+		fmt.Println("error: Committing is not possible because you have unmerged files.")
+		for _, path := range unmergedPaths {
+			fmt.Printf("\t%s\n", path)
+		}
+		fmt.Println("hint: Fix them up in the work tree, and then use 'git-go add <file>' as appropriate to mark resolution.")
+		os.Exit(1)
+	}
+
+	// Stopped commit may already be committed with commit command, which removes the state file
+	if stoppedHash, isStopped := utils.ReadStateFile(sequencerHeadFile(action)); isStopped {
+		commit, err := utils.ReadCommit(strings.TrimSpace(stoppedHash))
+		if err != nil {
+			log.Fatalln("Error when reading commit object:", err)
+		}
+		message, _ := utils.ReadStateFile("MERGE_MSG")
+		commitSequencerStep(action, commit, strings.TrimSpace(message))
+	}
+
+	runSequencer(action)
+}
+
+// Remove every state file of cherry-pick or revert
+func endSequencer(action string) {
+	utils.RemoveStateFiles(sequencerHeadFile(action), "MERGE_MSG")
+	err := utils.RemoveStateDir(sequencerStateDir)
+	if err != nil {
+		log.Fatalln("Error while removing sequencer state:", err)
+	}
+}
+
+func init() {
+	cherryPickCmd.Flags().BoolVar(&sequencerContinue, "continue", false, "Resume cherry-pick after resolving conflicts")
+	cherryPickCmd.Flags().BoolVar(&sequencerAbort, "abort", false, "Cancel cherry-pick and return to the pre-sequence state")
+	cherryPickCmd.Flags().BoolVar(&sequencerSkip, "skip", false, "Skip the current commit and continue with the rest")
+	rootCmd.AddCommand(cherryPickCmd)
+}
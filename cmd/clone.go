@@ -0,0 +1,258 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Kei-K23/git-go/internal/transport"
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var cloneNoHardlinks bool // Copy objects of local repository instead of hard linking them
+
+// cloneCmd represents the clone command
+var cloneCmd = &cobra.Command{
+	Use:   "clone <repository> [<directory>]",
+	Short: "Clone a repository into a new directory",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		url := args[0]
+
+		dir := cloneDirName(url)
+		if transport.IsLocalURL(url) {
+			// Local path is resolved before moving into clone folder, so it is stored as absolute path
+			absURL, err := filepath.Abs(strings.TrimPrefix(url, "file://"))
+			if err != nil {
+				log.Fatalln("Error while resolving repository path:", err)
+			}
+			if _, err := utils.FindLocalRepository(absURL); err != nil {
+				fmt.Printf("fatal: repository '%s' does not exist\n", url)
+				os.Exit(1)
+			}
+			url = absURL
+		}
+		if len(args) == 2 {
+			dir = args[1]
+		}
+
+		// Clone never writes into folder that already has files
+		if dirEntries, err := os.ReadDir(dir); err == nil && len(dirEntries) > 0 {
+			fmt.Printf("fatal: destination path '%s' already exists and is not an empty directory.\n", dir)
+			os.Exit(1)
+		}
+
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			log.Fatalln("Error while resolving clone directory:", err)
+		}
+
+		fmt.Printf("Cloning into '%s'...\n", dir)
+
+		err = cloneRepository(url, absDir)
+		if err != nil {
+			// Half cloned repository is useless, remove it
+			os.RemoveAll(absDir)
+			fmt.Printf("fatal: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// Get folder name for clone from repository URL (e.g https://example.com/repo.git -> repo)
+func cloneDirName(url string) string {
+	name := path.Base(strings.TrimRight(strings.ReplaceAll(url, "\\", "/"), "/"))
+	name = strings.TrimSuffix(name, utils.GitDirName)
+	name = strings.TrimSuffix(name, ".git")
+	name = strings.TrimRight(name, "/.")
+	if name == "" {
+		name = "repository"
+	}
+	return name
+}
+
+// Create repository in dir with origin remote, fetch everything and check out default branch of remote
+func cloneRepository(url string, dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		return err
+	}
+
+	utils.SetGitDir(utils.GitDirName)
+	err = utils.InitRepository("master")
+	if err != nil {
+		return err
+	}
+	err = utils.AddRemote("origin", url)
+	if err != nil {
+		return err
+	}
+
+	var branch, headHash string
+	if transport.IsLocalURL(url) {
+		branch, headHash, err = cloneLocalObjects(url)
+	} else {
+		var adv *transport.Advertisement
+		adv, err = fetchRemote("origin", true)
+		if err == nil {
+			branch, headHash = remoteDefaultBranch(adv)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if headHash == "" {
+		if len(utils.ListRefs("remotes")) > 0 {
+			fmt.Println("warning: remote HEAD refers to nonexistent ref, unable to checkout.")
+		} else {
+			fmt.Println("warning: You appear to have cloned an empty repository.")
+		}
+		if branch != "" {
+			return utils.SetHeadToBranch(branch, "")
+		}
+		return nil
+	}
+
+	err = utils.CheckoutCommit(headHash, true)
+	if err != nil {
+		return err
+	}
+
+	// Remote HEAD that is not on any branch is cloned as detached HEAD
+	if branch == "" {
+		return utils.SetDetachedHead(headHash, "clone: from "+url)
+	}
+
+	// HEAD points to the branch first, so creating the branch is recorded in HEAD reflog too
+	err = utils.SetHeadToBranch(branch, "")
+	if err != nil {
+		return err
+	}
+	err = utils.CreateBranch(branch, headHash, "clone: from "+url)
+	if err != nil {
+		return err
+	}
+
+	return setBranchUpstream(branch, "origin")
+}
+
+// Copy (or hard link) objects of local repository and create remote-tracking branches and tags from its refs
+// Branch and commit of source HEAD are returned
+func cloneLocalObjects(path string) (string, string, error) {
+	srcGitDir, err := utils.FindLocalRepository(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = utils.CopyObjects(srcGitDir, !cloneNoHardlinks)
+	if err != nil {
+		return "", "", err
+	}
+
+	refs, headContent, err := utils.ReadLocalRefs(srcGitDir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for name, hashValue := range refs {
+		if utils.ValidateRefName(name) != nil {
+			fmt.Printf("warning: ignoring ref with broken name %s\n", name)
+			continue
+		}
+		if branch, ok := strings.CutPrefix(name, "refs/heads/"); ok {
+			err = utils.UpdateRef("refs/remotes/origin/"+branch, hashValue, "clone: from "+path)
+		} else if strings.HasPrefix(name, "refs/tags/") {
+			err = utils.UpdateRef(name, hashValue, "clone: from "+path)
+		}
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	// HEAD is "ref: refs/heads/<branch>" or commit hash value of detached HEAD
+	if target, ok := strings.CutPrefix(headContent, "ref: "); ok {
+		branch, ok := headBranchName(target)
+		if !ok {
+			return "", "", fmt.Errorf("remote HEAD points to invalid branch '%s'", target)
+		}
+		return branch, refs[target], nil
+	}
+	return "", headContent, nil
+}
+
+// Find branch and commit remote HEAD is pointing to, symref capability is used and otherwise branch with same commit as HEAD
+func remoteDefaultBranch(adv *transport.Advertisement) (string, string) {
+	// Symref target that is not a valid branch is ignored, it becomes file path of local branch
+	if branch, ok := headBranchName(adv.HeadTarget()); ok {
+		target := "refs/heads/" + branch
+		if ref, ok := adv.Find(target); ok {
+			return branch, ref.Hash
+		}
+		return branch, "" // Remote HEAD points to unborn branch
+	}
+
+	// HEAD commit is only fetched when some fetched ref points to it
+	head, ok := adv.Find("HEAD")
+	if !ok || !utils.HasObject(head.Hash) {
+		return "", ""
+	}
+
+	// Prefer usual default branch names when several branches point to the same commit
+	for _, name := range []string{"refs/heads/master", "refs/heads/main"} {
+		if ref, ok := adv.Find(name); ok && ref.Hash == head.Hash {
+			return strings.TrimPrefix(name, "refs/heads/"), head.Hash
+		}
+	}
+	for _, ref := range adv.Refs {
+		if strings.HasPrefix(ref.Name, "refs/heads/") && ref.Hash == head.Hash {
+			return strings.TrimPrefix(ref.Name, "refs/heads/"), head.Hash
+		}
+	}
+
+	return "", head.Hash
+}
+
+// Record remote branch that local branch tracks in repository config
+func setBranchUpstream(branch string, remote string) error {
+	config, err := utils.LoadConfigFile(utils.RepoConfigPath())
+	if err != nil {
+		return err
+	}
+
+	err = config.Set("branch."+branch+".remote", remote)
+	if err != nil {
+		return err
+	}
+	err = config.Set("branch."+branch+".merge", "refs/heads/"+branch)
+	if err != nil {
+		return err
+	}
+
+	return config.Save(utils.RepoConfigPath())
+}
+
+func init() {
+	cloneCmd.Flags().BoolVar(&cloneNoHardlinks, "no-hardlinks", false, "Copy objects of local repository instead of using hard links")
+	rootCmd.AddCommand(cloneCmd)
+}
+
+// Get branch name of remote HEAD target (e.g refs/heads/main -> main), target must be a valid branch ref
+func headBranchName(target string) (string, bool) {
+	branch, ok := strings.CutPrefix(target, "refs/heads/")
+	if !ok || utils.ValidateRefName(branch) != nil {
+		return "", false
+	}
+	return branch, true
+}
@@ -4,7 +4,6 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"bytes"
 	"fmt"
 	"log"
 	"os"
@@ -15,6 +14,7 @@ import (
 )
 
 var commitMessage string // variable to store commit message
+var commitAmend bool     // variable to store --amend flag to replace the latest commit
 
 // commitCmd represents the commit command
 var commitCmd = &cobra.Command{
@@ -24,12 +24,55 @@ var commitCmd = &cobra.Command{
 		// TODO :: Must TODO
 		// Handle and check all updated files are added to staging area and if some file have changes that are different from staged file the commit should not be perform until all file are added to staging area
 
+		// Commit that finishes a merge has the merged commit as second parent
+		mergeHead, isMerging := utils.ReadStateFile("MERGE_HEAD")
+		mergeHead = strings.TrimSpace(mergeHead)
+
+		// Commit resolving conflicts of cherry-pick or revert finishes that commit
+		pickHead, isPicking := utils.ReadStateFile("CHERRY_PICK_HEAD")
+		_, isReverting := utils.ReadStateFile("REVERT_HEAD")
+
+		// Message prepared by merge, cherry-pick or revert command is used when -m is not given
+		if commitMessage == "" && (isMerging || isPicking || isReverting) {
+			mergeMessage, _ := utils.ReadStateFile("MERGE_MSG")
+			commitMessage = strings.TrimSpace(mergeMessage)
+		}
+
+		// Amended commit keeps its message unless -m is given
+		var amendedCommit utils.Commit
+		if commitAmend {
+			if isMerging {
+				fmt.Println("fatal: You are in the middle of a merge -- cannot amend.")
+				os.Exit(1)
+			}
+			headCommit := utils.GetCurrentCommit()
+			if headCommit == "" {
+				fmt.Println("fatal: You have nothing to amend.")
+				os.Exit(1)
+			}
+			var err error
+			amendedCommit, err = utils.ReadCommit(headCommit)
+			if err != nil {
+				log.Fatalln("Error when reading commit object:", err)
+			}
+			if commitMessage == "" {
+				commitMessage = strings.TrimSpace(amendedCommit.Message)
+			}
+		}
+
 		// Check -m flag is exist
 		if commitMessage == "" {
 			fmt.Println("No commit message provided. Use -m to provide a message.")
 			os.Exit(0) // Exit the command
 		}
 
+		// Author and committer identity come from user.name and user.email config
+		signature, err := utils.GetUserSignature()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
 		// Main logic to handle commit start here
 
 		// Read index file and get blobs to use as tree for project snapshot
@@ -39,97 +82,95 @@ var commitCmd = &cobra.Command{
 			os.Exit(0)
 		}
 
-		// Build tree object content
-		var sb strings.Builder
-		for _, entry := range entries {
-			var entryValue string
-			// If entry record only have one line, then don't use new line character at the end
-			if len(entries) > 1 {
-				entryValue = fmt.Sprintf("%s %s %s\n", entry.Mode, entry.Hash, entry.Path)
-			} else {
-				entryValue = fmt.Sprintf("%s %s %s", entry.Mode, entry.Hash, entry.Path)
+		// Conflicted files must be resolved and added before commit
+		if unmergedPaths := utils.UnmergedPaths(entries); len(unmergedPaths) > 0 {
+			fmt.Println("error: Committing is not possible because you have unmerged files.")
+			for _, path := range unmergedPaths {
+				fmt.Printf("\t%s\n", path)
 			}
-			sb.WriteString(entryValue)
+			fmt.Println("hint: Fix them up in the work tree, and then use 'git-go add <file>' as appropriate to mark resolution.")
+			os.Exit(1)
 		}
 
-		// Generate hash value for tree object
-		treeHash, err := utils.HandFileContent([]byte(sb.String()))
+		// Build tree objects (one per folder) from staged entries to use as project snapshot
+		treeHash, err := utils.WriteTree(entries)
 		if err != nil {
-			log.Fatalf("Cannot generate hash value for given file content")
+			log.Fatalln("Error when creating tree object:", err)
 		}
 
-		// Create tree object and store in objects folder
-		os.Mkdir(fmt.Sprintf(".git-go/objects/%s", treeHash[:2]), 0755)
-		blogFilePath := fmt.Sprintf(".git-go/objects/%s/%s", treeHash[:2], treeHash[2:])
-		treeObj, err := os.Create(blogFilePath)
+		// Get and check current commit hash value to add as parent commit
+		latestCommit := utils.GetCurrentCommit()
 
-		if err != nil {
-			log.Fatalln("Error when creating tree object")
+		// Snapshot is same as latest commit, so there is nothing new to record (merge and amended commits are still recorded)
+		if !isMerging && !commitAmend && latestCommit != "" && utils.GetCommitTreeHash(latestCommit) == treeHash {
+			fmt.Println("Nothing to commit. Working directory clean.")
+			os.Exit(0)
 		}
-		defer treeObj.Close()
 
-		// Create new zlib compress writer for tree obj
-		var treeCompressBuf bytes.Buffer
-		err = utils.CompressContent(&treeCompressBuf, []byte(sb.String()))
-		if err != nil {
-			log.Fatalln("Error when compressing file content")
+		commit := utils.Commit{
+			Tree:      treeHash,
+			Author:    signature,
+			Committer: signature,
+			Message:   commitMessage,
 		}
-
-		// Add compressed file content call blob to objects file
-		treeObj.Write(treeCompressBuf.Bytes())
-
-		// Create commit obj blob
-		var commitObjSb strings.Builder
-		commitObjSb.WriteString(fmt.Sprintf("tree %s\n", treeHash))
-
-		// Get and check current commit hash value to add as parent commit
-		latestCommit := utils.GetCurrentCommit()
-		if latestCommit != "" {
+		if commitAmend {
+			// Amended commit replaces the latest commit, so it takes over its parents and author
+			commit.Parents = amendedCommit.Parents
+			commit.Author = amendedCommit.Author
+		} else if latestCommit != "" {
 			// Add current commit hash value as parent commit when create new commit
-			commitObjSb.WriteString(fmt.Sprintf("parent %s\n", latestCommit))
+			commit.Parents = append(commit.Parents, latestCommit)
 		}
-
-		// Add author and committer info
-		// TODO : Handle hard coded value of author name and email
-		author := "author <author@gmail.com>" // Replace with dynamic values if needed
-		date := utils.GetCurrentTime()        // Use real date-time formatting
-
-		commitObjSb.WriteString(fmt.Sprintf("author %s %s\n", author, date))
-		commitObjSb.WriteString(fmt.Sprintf("committer %s %s\n", author, date))
-		commitObjSb.WriteString(fmt.Sprintf("\n%s\n", commitMessage))
-
-		commitObjHashValue, err := utils.HandFileContent([]byte(commitObjSb.String()))
-		if err != nil {
-			log.Fatalf("Cannot generate hash value for commit object")
+		if isPicking && !commitAmend {
+			// Picked commit keeps its original author, only committer is current user
+			pickedCommit, err := utils.ReadCommit(strings.TrimSpace(pickHead))
+			if err != nil {
+				log.Fatalln("Error when reading commit object:", err)
+			}
+			commit.Author = pickedCommit.Author
+		}
+		if isMerging {
+			commit.Parents = append(commit.Parents, mergeHead)
 		}
 
 		// Create commit object and store in objects folder
-		os.Mkdir(fmt.Sprintf(".git-go/objects/%s", commitObjHashValue[:2]), 0755)
-		commitObjFilePath := fmt.Sprintf(".git-go/objects/%s/%s", commitObjHashValue[:2], commitObjHashValue[2:])
-		commitObj, err := os.Create(commitObjFilePath)
-
+		commitObjHashValue, err := utils.WriteCommit(commit)
 		if err != nil {
 			log.Fatalln("Error when creating commit object")
 		}
-		defer commitObj.Close()
-
-		var commitObjCompressBuf bytes.Buffer
-		err = utils.CompressContent(&commitObjCompressBuf, []byte(commitObjSb.String()))
-		if err != nil {
-			log.Fatalln("Error when compressing commit object")
-		}
-		// Write compressed content to object file
-		commitObj.Write(commitObjCompressBuf.Bytes())
 
 		// Add commit hash value as current branch value
-		utils.UpdateCommitHashValue(commitObjHashValue)
+		utils.UpdateCommitHashValue(commitObjHashValue, commitReflogMessage(commit, latestCommit == "", isMerging, commitAmend))
 
-		fmt.Printf("[master %s] %s\n", commitObjHashValue, commitMessage)
+		// Merge (or cherry-pick or revert) is finished, so remove its state
+		if isMerging || isPicking || isReverting {
+			utils.RemoveStateFiles("MERGE_HEAD", "CHERRY_PICK_HEAD", "REVERT_HEAD", "MERGE_MSG")
+		}
+
+		currentBranch := utils.GerCurrentBranch()
+		if currentBranch == "" {
+			currentBranch = "detached HEAD"
+		}
+		fmt.Printf("[%s %s] %s\n", currentBranch, commitObjHashValue, commit.Subject())
 	},
 }
 
+// Reflog message of new commit, initial, merge and amended commits are marked (same as Git)
+func commitReflogMessage(commit utils.Commit, isInitial bool, isMerging bool, isAmend bool) string {
+	switch {
+	case isAmend:
+		return "commit (amend): " + commit.Subject()
+	case isInitial:
+		return "commit (initial): " + commit.Subject()
+	case isMerging:
+		return "commit (merge): " + commit.Subject()
+	}
+	return "commit: " + commit.Subject()
+}
+
 func init() {
 	// Register the -m flag
 	commitCmd.Flags().StringVarP(&commitMessage, "message", "m", "", "Commit message")
+	commitCmd.Flags().BoolVar(&commitAmend, "amend", false, "Amend previous commit")
 	rootCmd.AddCommand(commitCmd)
 }
@@ -0,0 +1,161 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var configGlobal bool // variable to store --global flag to use user level config
+var configLocal bool  // variable to store --local flag to use repository level config
+var configGet bool    // variable to store --get flag to get value of key
+var configGetAll bool // variable to store --get-all flag to get every value of key
+var configAdd bool    // variable to store --add flag to add value without replacing
+var configUnset bool  // variable to store --unset flag to remove key
+var configList bool   // variable to store --list flag to list every key
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config [--global | --local] [--get | --get-all | --add | --unset | --list] [<key> [<value>]]",
+	Short: "Get and set repository or global options",
+	Args:  cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if configGlobal && configLocal {
+			fmt.Println("error: only one config file at a time")
+			os.Exit(1)
+		}
+
+		// Repository config is only available inside repository, reading without it falls back to user config
+		hasRepository := false
+		if !configGlobal {
+			err := utils.SetupRepository(gitDirPath, workTreePath)
+			if err == nil {
+				hasRepository = true
+			} else if configLocal || configAdd || configUnset || len(args) == 2 {
+				fmt.Printf("fatal: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Reading without scope uses merged config, writing without scope uses repository config
+		configPath := utils.RepoConfigPath()
+		if configGlobal || !hasRepository {
+			configPath = utils.GlobalConfigPath()
+		}
+
+		switch {
+		case configList:
+			var config *utils.Config
+			var err error
+			if configGlobal || configLocal || !hasRepository {
+				config, err = utils.LoadConfigFile(configPath)
+			} else {
+				config, err = utils.LoadConfig()
+			}
+			if err != nil {
+				log.Fatalln("fatal: bad config file:", err)
+			}
+
+			for _, entry := range config.List() {
+				fmt.Printf("%s=%s\n", entry.Key, entry.Value)
+			}
+
+		case configUnset:
+			if len(args) != 1 {
+				fmt.Println("error: wrong number of arguments, should be 1")
+				os.Exit(1)
+			}
+			config := loadConfigForUpdate(configPath)
+			if !config.Unset(args[0]) {
+				os.Exit(5) // Same exit code as Git when key doesn't exist
+			}
+			saveConfig(config, configPath)
+
+		case configAdd || len(args) == 2:
+			if len(args) != 2 {
+				fmt.Println("error: wrong number of arguments, should be 2")
+				os.Exit(1)
+			}
+			config := loadConfigForUpdate(configPath)
+
+			var err error
+			if configAdd {
+				err = config.Add(args[0], args[1])
+			} else {
+				err = config.Set(args[0], args[1])
+			}
+			if err != nil {
+				fmt.Printf("error: %v\n", err)
+				os.Exit(1)
+			}
+			saveConfig(config, configPath)
+
+		case len(args) == 1:
+			// --get is the default action for single key
+			var config *utils.Config
+			var err error
+			if configGlobal || configLocal || !hasRepository {
+				config, err = utils.LoadConfigFile(configPath)
+			} else {
+				config, err = utils.LoadConfig()
+			}
+			if err != nil {
+				log.Fatalln("fatal: bad config file:", err)
+			}
+
+			if _, _, _, err := utils.SplitConfigKey(args[0]); err != nil {
+				fmt.Printf("error: %v\n", err)
+				os.Exit(1)
+			}
+
+			values := config.GetAll(args[0])
+			if len(values) == 0 {
+				os.Exit(1)
+			}
+			if !configGetAll {
+				values = values[len(values)-1:]
+			}
+			for _, value := range values {
+				fmt.Println(value)
+			}
+
+		default:
+			cmd.Usage()
+			os.Exit(1)
+		}
+	},
+}
+
+// Load config file that is going to be changed
+func loadConfigForUpdate(configPath string) *utils.Config {
+	config, err := utils.LoadConfigFile(configPath)
+	if err != nil {
+		log.Fatalln("fatal: bad config file:", err)
+	}
+	return config
+}
+
+// Save changed config file
+func saveConfig(config *utils.Config, configPath string) {
+	err := config.Save(configPath)
+	if err != nil {
+		log.Fatalln("error: could not write config file:", err)
+	}
+}
+
+func init() {
+	configCmd.Flags().BoolVar(&configGlobal, "global", false, "Use user level config file")
+	configCmd.Flags().BoolVar(&configLocal, "local", false, "Use repository config file")
+	configCmd.Flags().BoolVar(&configGet, "get", false, "Get value of the key")
+	configCmd.Flags().BoolVar(&configGetAll, "get-all", false, "Get every value of the key")
+	configCmd.Flags().BoolVar(&configAdd, "add", false, "Add new value without replacing existing values")
+	configCmd.Flags().BoolVar(&configUnset, "unset", false, "Remove the key")
+	configCmd.Flags().BoolVarP(&configList, "list", "l", false, "List every key and value")
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,201 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/Kei-K23/git-go/internal/diff"
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var diffCached bool // variable to store cached flag to compare staging area with commit
+var diffContext int // variable to store number of context lines around changes
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [--cached] [<commit> [<commit>]]",
+	Short: "Show changes between commits, commit and working tree, etc",
+	Args:  cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if diffContext < 0 {
+			fmt.Printf("fatal: invalid context line count '%d' for -U/--unified, it must not be negative\n", diffContext)
+			os.Exit(1)
+		}
+
+		indexEntries, err := utils.ReadIndexFile()
+		if err != nil {
+			log.Fatalln("Error while reading index file")
+		}
+
+		// Conflicted files have no single staged version, so they are only reported as unmerged
+		unmergedPaths := utils.UnmergedPaths(indexEntries)
+		var stagedEntries []utils.IndexEntry
+		for _, entry := range indexEntries {
+			if entry.Stage() == 0 {
+				stagedEntries = append(stagedEntries, entry)
+			}
+		}
+
+		switch {
+		case len(args) == 2:
+			// Compare two commits
+			printTreeDiff(resolveDiffCommit(args[0]), resolveDiffCommit(args[1]), false)
+		case diffCached:
+			// Compare commit (HEAD by default) with staging area
+			var oldEntries []utils.IndexEntry
+			if len(args) == 1 {
+				oldEntries = resolveDiffCommit(args[0])
+			} else {
+				oldEntries = utils.GetCommitFiles(utils.GetCurrentCommit())
+			}
+			printUnmergedPaths(unmergedPaths)
+			printTreeDiff(oldEntries, stagedEntries, false)
+		case len(args) == 1:
+			// Compare commit with working tree files that are tracked
+			printTreeDiff(resolveDiffCommit(args[0]), workingEntries(indexEntries), true)
+		default:
+			// Compare staging area with working tree
+			printUnmergedPaths(unmergedPaths)
+			printTreeDiff(stagedEntries, workingEntries(stagedEntries), true)
+		}
+	},
+}
+
+// Resolve revision and get files of that commit
+func resolveDiffCommit(revision string) []utils.IndexEntry {
+	commitHash, err := utils.ResolveRevision(revision)
+	if err != nil {
+		fmt.Printf("fatal: %v\n", err)
+		os.Exit(1)
+	}
+	return utils.GetCommitFiles(commitHash)
+}
+
+// Print conflicted paths that can't be compared
+func printUnmergedPaths(paths []string) {
+	for _, path := range paths {
+		fmt.Printf("* Unmerged path %s\n", path)
+	}
+}
+
+// Get working tree version of tracked files, the hash value is computed from current file content
+func workingEntries(indexEntries []utils.IndexEntry) []utils.IndexEntry {
+	var entries []utils.IndexEntry
+	seen := make(map[string]bool)
+	for _, entry := range indexEntries {
+		// Conflicted file has several staged versions but only one working tree version
+		if seen[entry.Path] {
+			continue
+		}
+		seen[entry.Path] = true
+
+		workingHash, err := utils.HashWorkingFile(entry.Path)
+		if err != nil {
+			continue // File is deleted from working tree
+		}
+		entries = append(entries, utils.IndexEntry{Mode: entry.Mode, Hash: workingHash, Path: entry.Path})
+	}
+	return entries
+}
+
+// Print unified diff of every file that is different between old and new entries
+func printTreeDiff(oldEntries, newEntries []utils.IndexEntry, newFromWorkingTree bool) {
+	oldFiles := utils.EntriesToMap(oldEntries)
+	newFiles := utils.EntriesToMap(newEntries)
+
+	pathSet := make(map[string]bool)
+	for path := range oldFiles {
+		pathSet[path] = true
+	}
+	for path := range newFiles {
+		pathSet[path] = true
+	}
+	var paths []string
+	for path := range pathSet {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		oldEntry, inOld := oldFiles[path]
+		newEntry, inNew := newFiles[path]
+		if inOld && inNew && oldEntry.SameContent(newEntry) {
+			continue
+		}
+
+		var oldContent, newContent []byte
+		if inOld {
+			oldContent = readDiffBlob(oldEntry.Hash)
+		}
+		if inNew && newFromWorkingTree {
+			content, err := utils.ReadWorkingFile(path)
+			if err != nil {
+				log.Fatalf("Cannot read the content of file '%s'", path)
+			}
+			newContent = content
+		} else if inNew {
+			newContent = readDiffBlob(newEntry.Hash)
+		}
+
+		printFileDiff(path, oldEntry, newEntry, inOld, inNew, oldContent, newContent)
+	}
+}
+
+// Read blob content to compare
+func readDiffBlob(hashValue string) []byte {
+	content, err := utils.ReadBlobObject(hashValue)
+	if err != nil {
+		log.Fatalf("Error while reading blob object '%s': %v", hashValue, err)
+	}
+	return content
+}
+
+// Print diff header and hunks of single file in Git format
+func printFileDiff(path string, oldEntry, newEntry utils.IndexEntry, inOld, inNew bool, oldContent, newContent []byte) {
+	fmt.Printf("diff --git a/%s b/%s\n", path, path)
+
+	oldHash, newHash := "0000000", "0000000"
+	oldName, newName := "a/"+path, "b/"+path
+	switch {
+	case !inOld:
+		fmt.Printf("new file mode %s\n", newEntry.Mode)
+		oldName = "/dev/null"
+		newHash = newEntry.Hash[:7]
+		fmt.Printf("index %s..%s\n", oldHash, newHash)
+	case !inNew:
+		fmt.Printf("deleted file mode %s\n", oldEntry.Mode)
+		newName = "/dev/null"
+		oldHash = oldEntry.Hash[:7]
+		fmt.Printf("index %s..%s\n", oldHash, newHash)
+	default:
+		if oldEntry.Mode != newEntry.Mode {
+			fmt.Printf("old mode %s\nnew mode %s\n", oldEntry.Mode, newEntry.Mode)
+		}
+		oldHash, newHash = oldEntry.Hash[:7], newEntry.Hash[:7]
+		if oldHash == newHash {
+			return // Only mode is changed
+		}
+		fmt.Printf("index %s..%s %s\n", oldHash, newHash, newEntry.Mode)
+	}
+
+	if diff.IsBinary(oldContent) || diff.IsBinary(newContent) {
+		fmt.Printf("Binary files %s and %s differ\n", oldName, newName)
+		return
+	}
+
+	fmt.Printf("--- %s\n", oldName)
+	fmt.Printf("+++ %s\n", newName)
+	fmt.Print(diff.Unified(oldContent, newContent, diffContext))
+}
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffCached, "cached", false, "Show changes between staging area and commit")
+	diffCmd.Flags().IntVarP(&diffContext, "unified", "U", 3, "Number of context lines")
+	rootCmd.AddCommand(diffCmd)
+}
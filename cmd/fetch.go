@@ -0,0 +1,242 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Kei-K23/git-go/internal/transport"
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// Most commits sent as "have" during negotiation, remote only needs recent history to find common commits
+const maxFetchHaves = 256
+
+// fetchCmd represents the fetch command
+var fetchCmd = &cobra.Command{
+	Use:   "fetch [<remote>]",
+	Short: "Download objects and refs from another repository",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		remote := "origin"
+		if len(args) == 1 {
+			remote = args[0]
+		}
+
+		_, err := fetchRemote(remote, false)
+		if err != nil {
+			fmt.Printf("fatal: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// Ref that is updated by fetch
+type fetchUpdate struct {
+	remoteRef string
+	localRef  string
+	oldHash   string
+	newHash   string
+	force     bool
+}
+
+// Download missing objects from remote and update remote-tracking refs mapped by fetch refspecs
+// With includeTags every remote tag is fetched, otherwise only tags pointing to fetched history are followed
+func fetchRemote(remote string, includeTags bool) (*transport.Advertisement, error) {
+	url, ok := utils.GetRemoteURL(remote)
+	if !ok {
+		return nil, fmt.Errorf("'%s' does not appear to be a git repository", remote)
+	}
+
+	refSpecs, err := utils.GetRemoteFetchSpecs(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := transport.Open(url)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	adv, err := conn.Advertise()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ref names come from remote and become file paths, broken names (e.g "refs/tags/../../x") are ignored
+	validRefs := adv.Refs[:0]
+	for _, ref := range adv.Refs {
+		if !isValidRemoteRef(ref.Name) {
+			fmt.Printf("warning: ignoring ref with broken name %s\n", ref.Name)
+			continue
+		}
+		validRefs = append(validRefs, ref)
+	}
+	adv.Refs = validRefs
+
+	var updates []fetchUpdate
+	for _, ref := range adv.Refs {
+		for _, refSpec := range refSpecs {
+			if localRef, ok := refSpec.MapSource(ref.Name); ok {
+				if !isValidRemoteRef(localRef) {
+					fmt.Printf("warning: ignoring ref with broken name %s\n", localRef)
+					continue
+				}
+				updates = append(updates, fetchUpdate{ref.Name, localRef, utils.ReadRef(localRef), ref.Hash, refSpec.Force})
+			}
+		}
+		if includeTags && strings.HasPrefix(ref.Name, "refs/tags/") && utils.ReadRef(ref.Name) == "" {
+			updates = append(updates, fetchUpdate{ref.Name, ref.Name, "", ref.Hash, false})
+		}
+	}
+
+	// Only objects we don't have yet are requested
+	var wants []string
+	wanted := make(map[string]bool)
+	for _, update := range updates {
+		if !wanted[update.newHash] && !utils.HasObject(update.newHash) {
+			wanted[update.newHash] = true
+			wants = append(wants, update.newHash)
+		}
+	}
+
+	if len(wants) > 0 {
+		packData, err := conn.FetchPack(adv, wants, localHaves(), os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		_, err = utils.StorePack(packData)
+		if err != nil {
+			return nil, err
+		}
+		for _, want := range wants {
+			if !utils.HasObject(want) {
+				return nil, fmt.Errorf("remote did not send all necessary objects (missing %s)", want)
+			}
+		}
+	}
+
+	// Follow tags that point into history we have now
+	if !includeTags {
+		for _, ref := range adv.Refs {
+			if strings.HasPrefix(ref.Name, "refs/tags/") && utils.ReadRef(ref.Name) == "" && utils.HasObject(ref.Hash) {
+				updates = append(updates, fetchUpdate{ref.Name, ref.Name, "", ref.Hash, false})
+			}
+		}
+	}
+
+	printedURL := false
+	for _, update := range updates {
+		if update.oldHash == update.newHash {
+			continue
+		}
+		if !printedURL {
+			fmt.Printf("From %s\n", url)
+			printedURL = true
+		}
+
+		summary, flag, reason := "", " ", ""
+		logMessage := "fetch " + remote + ": storing head"
+		remoteName, localName := shortRefName(update.remoteRef), shortRefName(update.localRef)
+		switch {
+		case update.oldHash == "" && strings.HasPrefix(update.remoteRef, "refs/tags/"):
+			summary, flag = "[new tag]", "*"
+		case update.oldHash == "":
+			summary, flag = "[new branch]", "*"
+		default:
+			isFastForward, err := utils.IsAncestor(update.oldHash, update.newHash)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case isFastForward:
+				summary = update.oldHash[:7] + ".." + update.newHash[:7]
+				logMessage = "fetch " + remote + ": fast-forward"
+			case update.force:
+				summary, flag, reason = update.oldHash[:7]+"..."+update.newHash[:7], "+", "  (forced update)"
+				logMessage = "fetch " + remote + ": forced-update"
+			default:
+				fmt.Printf(" ! %-17s %-10s -> %s  (non-fast-forward)\n", "[rejected]", remoteName, localName)
+				continue
+			}
+		}
+
+		err = utils.UpdateRef(update.localRef, update.newHash, logMessage)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf(" %s %-17s %-10s -> %s%s\n", flag, summary, remoteName, localName, reason)
+	}
+
+	return adv, nil
+}
+
+// Check ref name from remote (or mapped from it) is safe to store, it must be HEAD or a valid name under refs/
+func isValidRemoteRef(name string) bool {
+	return name == "HEAD" || (strings.HasPrefix(name, "refs/") && utils.ValidateRefName(name) == nil)
+}
+
+// Collect recent commits reachable from local refs, newest first, to tell remote which history we already have
+func localHaves() []string {
+	var tips []string
+	if head := utils.GetCurrentCommit(); head != "" {
+		tips = append(tips, head)
+	}
+	for _, kind := range []string{"heads", "remotes", "tags"} {
+		for _, name := range utils.ListRefs(kind) {
+			if hashValue, err := utils.ResolveRevision("refs/" + kind + "/" + name); err == nil {
+				tips = append(tips, hashValue)
+			}
+		}
+	}
+
+	var haves []string
+	var pending []utils.Commit
+	visited := make(map[string]bool)
+	for _, tip := range tips {
+		if commit, err := utils.ReadCommit(tip); err == nil && !visited[tip] {
+			visited[tip] = true
+			pending = append(pending, commit)
+		}
+	}
+
+	for len(pending) > 0 && len(haves) < maxFetchHaves {
+		newest := 0
+		for i, commit := range pending {
+			if commit.Committer.When.After(pending[newest].Committer.When) {
+				newest = i
+			}
+		}
+		current := pending[newest]
+		pending = append(pending[:newest], pending[newest+1:]...)
+		haves = append(haves, current.Hash)
+
+		for _, parent := range current.Parents {
+			if commit, err := utils.ReadCommit(parent); err == nil && !visited[parent] {
+				visited[parent] = true
+				pending = append(pending, commit)
+			}
+		}
+	}
+
+	return haves
+}
+
+// Shorten reference name for output (e.g refs/remotes/origin/main -> origin/main)
+func shortRefName(name string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/tags/", "refs/remotes/"} {
+		if short, ok := strings.CutPrefix(name, prefix); ok {
+			return short
+		}
+	}
+	return name
+}
+
+func init() {
+	rootCmd.AddCommand(fetchCmd)
+}
@@ -0,0 +1,36 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// gcCmd represents the gc command
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Cleanup unnecessary files and optimize the local repository",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Same as `repack -a -d`, every object ends up in a single pack and packed loose objects are pruned
+		packName, count, pruned, err := repackObjects(true, true, utils.DefaultPackOptions)
+		if err != nil {
+			log.Fatalln("Error while packing objects:", err)
+		}
+
+		if packName == "" {
+			fmt.Println("Nothing to pack.")
+			return
+		}
+		fmt.Printf("Packed %d objects into %s, pruned %d loose objects\n", count, packName, pruned)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/Kei-K23/git-go/pkg/object"
+	"github.com/spf13/cobra"
+)
+
+var hashObjectWrite bool  // variable to store -w flag to write object into object database
+var hashObjectStdin bool  // variable to store --stdin flag to read object content from standard input
+var hashObjectType string // variable to store object type (blob by default)
+
+// hashObjectCmd represents the hash-object command
+var hashObjectCmd = &cobra.Command{
+	Use:   "hash-object [-w] [-t <type>] [--stdin] [<file>...]",
+	Short: "Compute object ID and optionally create an object from a file",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !utils.IsValidObjectType(hashObjectType) {
+			fmt.Printf("fatal: invalid object type \"%s\"\n", hashObjectType)
+			os.Exit(1)
+		}
+
+		if !hashObjectStdin && len(args) == 0 {
+			fmt.Println("usage: git-go hash-object [-w] [-t <type>] [--stdin] [<file>...]")
+			os.Exit(1)
+		}
+
+		// Read every content before repository setup, because setup moves into working tree root
+		var contents [][]byte
+		if hashObjectStdin {
+			content, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				log.Fatalln("Error while reading standard input:", err)
+			}
+			contents = append(contents, content)
+		}
+		for _, file := range args {
+			content, err := os.ReadFile(filepath.Clean(file))
+			if err != nil {
+				fmt.Printf("fatal: could not open '%s' for reading: %v\n", file, err)
+				os.Exit(1)
+			}
+			contents = append(contents, content)
+		}
+
+		if hashObjectWrite {
+			setupRepository()
+		}
+
+		for _, content := range contents {
+			err := validateObjectContent(hashObjectType, content)
+			if err != nil {
+				fmt.Printf("fatal: corrupt %s: %v\n", hashObjectType, err)
+				os.Exit(1)
+			}
+
+			var hashValue string
+			if hashObjectWrite {
+				hashValue, err = utils.WriteObject(hashObjectType, content)
+			} else {
+				hashValue, err = utils.HashObject(hashObjectType, content)
+			}
+			if err != nil {
+				log.Fatalln("Error while hashing object:", err)
+			}
+
+			fmt.Println(hashValue)
+		}
+	},
+}
+
+// Make sure content has valid format of the object type, so broken object is never written
+func validateObjectContent(objType string, content []byte) error {
+	_, err := object.Parse(object.Type(objType), content)
+	return err
+}
+
+func init() {
+	hashObjectCmd.Flags().BoolVarP(&hashObjectWrite, "write", "w", false, "Write the object into the object database")
+	hashObjectCmd.Flags().BoolVar(&hashObjectStdin, "stdin", false, "Read the object from standard input")
+	hashObjectCmd.Flags().StringVarP(&hashObjectType, "type", "t", utils.BlobObject, "Type of object to create")
+	rootCmd.AddCommand(hashObjectCmd)
+}
@@ -5,7 +5,7 @@ package cmd
 
 import (
 	"fmt"
-	"strings"
+	"log"
 
 	"github.com/Kei-K23/git-go/internal/utils"
 	"github.com/spf13/cobra"
@@ -21,59 +21,60 @@ var logCmd = &cobra.Command{
 
 		// E.g Output for -log command
 		// commit 20b94005ce5cc553525322d91b8eb3c9b7c79532 (HEAD -> main, origin/main)
+		// Merge: 1a2b3c4 5d6e7f8
 		// Author: Author-A22 <aaa2301@gmail.com>
 		// Date:   Tue Sep 24 15:54:09 2024 +0630
 
-		// Format commit content to use in -log command
-		// Track for parent tree
-		currentCommit := latestCommit
+		if latestCommit == "" {
+			return
+		}
 
-		for currentCommit != "" {
-			// Get the current commit object
-			currentCommitBytes := utils.ReadCommitObject(currentCommit)
-			currentCommitContent := string(currentCommitBytes)
-			parts := strings.Split(currentCommitContent, "\n")
+		// Commits waiting to be shown, merge commit adds every parent so history of all merged branches is shown
+		var pending []utils.Commit
+		visited := make(map[string]bool)
 
-			var author, date, message, parentCommit string
+		addCommit := func(hashValue string) {
+			if visited[hashValue] {
+				return
+			}
+			visited[hashValue] = true
 
-			for _, part := range parts {
-				// Parse author information
-				if strings.HasPrefix(part, "author") && part != "" {
-					// Example for author content (e.g author author <author@gmail.com> 2024-09-25T19:32:44+06:30)
-					authorParts := strings.SplitN(part, " ", 3)
-					author = authorParts[1] + " " + authorParts[2]
-				}
+			commit, err := utils.ReadCommit(hashValue)
+			if err != nil {
+				log.Fatalln("Error when reading commit object:", err)
+			}
+			pending = append(pending, commit)
+		}
 
-				if strings.HasPrefix(part, "committer") && part != "" {
-					// Example for committer content (e.g committer author <author@gmail.com> 2024-09-25T19:32:44+06:30)
-					dateParts := strings.SplitN(part, " ", 3)
-					date = dateParts[2]
-				}
+		addCommit(latestCommit)
 
-				// Parse parent commit, if it is exist
-				if strings.HasPrefix(part, "parent") && part != "" {
-					parentParts := strings.Split(part, " ")
-					parentCommit = parentParts[1] // Get the parent hash value
+		for len(pending) > 0 {
+			// Show the newest pending commit first (same order as Git)
+			newest := 0
+			for i, commit := range pending {
+				if commit.Committer.When.After(pending[newest].Committer.When) {
+					newest = i
 				}
+			}
+			currentCommit := pending[newest]
+			pending = append(pending[:newest], pending[newest+1:]...)
 
-				// Parse message content
-				if part == "" && len(part) == 0 {
-					// Commit message starts after the first empty line
-					messageIndex := strings.Index(currentCommitContent, "\n\n")
-
-					if messageIndex != -1 {
-						message = currentCommitContent[messageIndex+2:]
-					}
+			fmt.Printf("commit %s\n", currentCommit.Hash)
+			if len(currentCommit.Parents) > 1 {
+				fmt.Print("Merge:")
+				for _, parent := range currentCommit.Parents {
+					fmt.Printf(" %s", parent[:7])
 				}
+				fmt.Println()
 			}
+			fmt.Printf("Author: %s <%s>\n", currentCommit.Author.Name, currentCommit.Author.Email)
+			fmt.Printf("Date:   %s\n", utils.FormatSignatureTime(currentCommit.Author.When))
+			fmt.Printf("\t%s\n", currentCommit.Message)
 
-			fmt.Printf("commit %s\n", currentCommit)
-			fmt.Printf("Author %s\n", author)
-			fmt.Printf("Date %s\n", date)
-			fmt.Printf("\t%s\n", message)
-
-			// Move to parent commit object
-			currentCommit = parentCommit
+			// Move to parent commit objects
+			for _, parent := range currentCommit.Parents {
+				addCommit(parent)
+			}
 		}
 	},
 }
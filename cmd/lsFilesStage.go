@@ -28,7 +28,7 @@ var lsFilesStageCmd = &cobra.Command{
 		}
 
 		for _, entry := range entries {
-			fmt.Printf("%s %s %s\n", entry.Mode, entry.Hash, entry.Path)
+			fmt.Printf("%s %s %d\t%s\n", entry.Mode, entry.Hash, entry.Stage(), entry.Path)
 		}
 	},
 }
@@ -0,0 +1,200 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var mergeNoFastForward bool // variable to store --no-ff flag to always create merge commit
+var mergeMessage string     // variable to store merge commit message
+var mergeAbort bool         // variable to store --abort flag to cancel conflicted merge
+
+// mergeCmd represents the merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge [--no-ff] [-m <msg>] <commit> | --abort",
+	Short: "Join two development histories together",
+	Run: func(cmd *cobra.Command, args []string) {
+		if mergeAbort {
+			if _, isMerging := utils.ReadStateFile("MERGE_HEAD"); !isMerging {
+				fmt.Println("fatal: There is no merge to abort (MERGE_HEAD missing).")
+				os.Exit(1)
+			}
+
+			// Throw away merge result and go back to HEAD snapshot
+			err := utils.CheckoutCommit(utils.GetCurrentCommit(), true)
+			if err != nil {
+				log.Fatalln("Error while aborting merge:", err)
+			}
+			utils.RemoveStateFiles("MERGE_HEAD", "MERGE_MSG")
+			return
+		}
+
+		if len(args) != 1 {
+			fmt.Println("fatal: exactly one commit to merge is required")
+			os.Exit(1)
+		}
+
+		if _, isMerging := utils.ReadStateFile("MERGE_HEAD"); isMerging {
+			fmt.Println("fatal: You have not concluded your merge (MERGE_HEAD exists).")
+			fmt.Println("Please, commit your changes before you merge.")
+			os.Exit(1)
+		}
+
+		name := args[0]
+		theirsCommit, err := utils.ResolveRevision(name)
+		if err != nil {
+			fmt.Printf("merge: %s - not something we can merge\n", name)
+			os.Exit(1)
+		}
+
+		// Merge must start from clean state so conflicts only contain changes of both commits
+		localChanges, err := utils.LocalChanges()
+		if err != nil {
+			log.Fatalln("Error while reading index file:", err)
+		}
+		if len(localChanges) > 0 {
+			fmt.Println("error: Your local changes to the following files would be overwritten by merge:")
+			for _, path := range localChanges {
+				fmt.Printf("\t%s\n", path)
+			}
+			fmt.Println("Please commit your changes before you merge.")
+			os.Exit(1)
+		}
+
+		oursCommit := utils.GetCurrentCommit()
+
+		// Unborn branch simply starts from the merged commit
+		if oursCommit == "" {
+			err = utils.CheckoutCommit(theirsCommit, false)
+			if err != nil {
+				fmt.Printf("error: %v\n", err)
+				os.Exit(1)
+			}
+			utils.UpdateCommitHashValue(theirsCommit, "merge "+name+": Fast-forward")
+			return
+		}
+
+		if isAncestor, err := utils.IsAncestor(theirsCommit, oursCommit); err != nil {
+			log.Fatalln("Error while reading commit history:", err)
+		} else if isAncestor {
+			fmt.Println("Already up to date.")
+			return
+		}
+
+		baseCommit, err := utils.MergeBase(oursCommit, theirsCommit)
+		if err != nil {
+			log.Fatalln("Error while finding merge base:", err)
+		}
+
+		// Current commit is ancestor of merged commit, so just move forward to it
+		if baseCommit == oursCommit && !mergeNoFastForward {
+			fmt.Printf("Updating %s..%s\n", oursCommit[:7], theirsCommit[:7])
+			err = utils.CheckoutCommit(theirsCommit, false)
+			if err != nil {
+				fmt.Printf("error: %v\n", err)
+				os.Exit(1)
+			}
+			utils.UpdateCommitHashValue(theirsCommit, "merge "+name+": Fast-forward")
+			fmt.Println("Fast-forward")
+			return
+		}
+
+		result, err := utils.MergeTrees(utils.GetCommitFiles(baseCommit), utils.GetCommitFiles(oursCommit), utils.GetCommitFiles(theirsCommit), "HEAD", name)
+		if err != nil {
+			log.Fatalln("Error while merging:", err)
+		}
+
+		err = utils.ApplyMergeResult(result)
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+			os.Exit(1)
+		}
+
+		message := mergeMessage
+		if message == "" {
+			message = defaultMergeMessage(name)
+		}
+
+		// Conflicts must be resolved by user, commit command finishes the merge later
+		if len(result.Conflicts) > 0 {
+			err = utils.WriteStateFile("MERGE_HEAD", theirsCommit+"\n")
+			if err == nil {
+				err = utils.WriteStateFile("MERGE_MSG", message+"\n")
+			}
+			if err != nil {
+				log.Fatalln("Error while saving merge state:", err)
+			}
+
+			// Conflicted path misses ours or theirs stage when one side deleted it
+			stages := make(map[string]int)
+			for _, entry := range result.Entries {
+				stages[entry.Path] |= 1 << entry.Stage()
+			}
+			for _, path := range result.Conflicts {
+				switch {
+				case stages[path]&(1<<3) == 0:
+					fmt.Printf("CONFLICT (modify/delete): %s deleted in %s and modified in HEAD.\n", path, name)
+				case stages[path]&(1<<2) == 0:
+					fmt.Printf("CONFLICT (modify/delete): %s deleted in HEAD and modified in %s.\n", path, name)
+				default:
+					fmt.Printf("CONFLICT (content): Merge conflict in %s\n", path)
+				}
+			}
+			fmt.Println("Automatic merge failed; fix conflicts and then commit the result.")
+			os.Exit(1)
+		}
+
+		signature, err := utils.GetUserSignature()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		treeHash, err := utils.WriteTree(result.Entries)
+		if err != nil {
+			log.Fatalln("Error when creating tree object:", err)
+		}
+
+		mergeCommit, err := utils.WriteCommit(utils.Commit{
+			Tree:      treeHash,
+			Parents:   []string{oursCommit, theirsCommit},
+			Author:    signature,
+			Committer: signature,
+			Message:   message,
+		})
+		if err != nil {
+			log.Fatalln("Error when creating commit object:", err)
+		}
+		utils.UpdateCommitHashValue(mergeCommit, "merge "+name+": Merge made by the 'three-way' strategy.")
+
+		fmt.Println("Merge made by the 'three-way' strategy.")
+	},
+}
+
+// Create default merge commit message (e.g Merge branch 'dev')
+func defaultMergeMessage(name string) string {
+	if utils.BranchExists(name) {
+		return fmt.Sprintf("Merge branch '%s'", name)
+	}
+	if utils.TagExists(name) {
+		return fmt.Sprintf("Merge tag '%s'", name)
+	}
+	if utils.ReadRef("refs/remotes/"+name) != "" {
+		return fmt.Sprintf("Merge remote-tracking branch '%s'", name)
+	}
+	return fmt.Sprintf("Merge commit '%s'", name)
+}
+
+func init() {
+	mergeCmd.Flags().BoolVar(&mergeNoFastForward, "no-ff", false, "Create a merge commit even when the merge resolves as a fast-forward")
+	mergeCmd.Flags().StringVarP(&mergeMessage, "message", "m", "", "Merge commit message")
+	mergeCmd.Flags().BoolVar(&mergeAbort, "abort", false, "Abort the current conflict resolution process")
+	rootCmd.AddCommand(mergeCmd)
+}
@@ -0,0 +1,157 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var mvForce bool // Overwrite destination file when it already exists
+
+// mvCmd represents the mv command
+var mvCmd = &cobra.Command{
+	Use:   "mv [-f] <source>... <destination>",
+	Short: "Move or rename a file, a directory, or a symlink",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var paths []string
+		for _, arg := range args {
+			repoPath, err := utils.ToRepoPath(arg)
+			if err != nil {
+				log.Fatalf("fatal: %v", err)
+			}
+			paths = append(paths, repoPath)
+		}
+		sources, destination := paths[:len(paths)-1], paths[len(paths)-1]
+
+		// Sources are moved inside destination folder when it exists, several sources always need one
+		destInfo, err := os.Stat(destination)
+		intoFolder := err == nil && destInfo.IsDir()
+		if len(sources) > 1 && !intoFolder {
+			fmt.Printf("fatal: destination '%s' is not a directory\n", destination)
+			os.Exit(1)
+		}
+
+		entries, err := utils.ReadIndexFile()
+		if err != nil {
+			log.Fatalln("Error while reading index file")
+		}
+
+		// Check every move before anything is changed
+		targets := make([]string, len(sources))
+		seenTargets := make(map[string]bool)
+		for i, source := range sources {
+			target := destination
+			if intoFolder {
+				target = path.Join(destination, path.Base(source))
+			}
+			targets[i] = target
+
+			// Target doesn't exist yet for any of them, so second move would silently replace the first one
+			err := checkMove(source, target, entries)
+			if err == nil && seenTargets[target] {
+				err = errors.New("multiple sources for the same target")
+			}
+			if err != nil {
+				fmt.Printf("fatal: %v, source=%s, destination=%s\n", err, source, target)
+				os.Exit(1)
+			}
+			seenTargets[target] = true
+		}
+
+		for i, source := range sources {
+			target := targets[i]
+
+			// Destination file is only overwritten with -f (checked above)
+			if targetInfo, err := os.Lstat(target); err == nil && !targetInfo.IsDir() {
+				os.Remove(target)
+			}
+			err := os.Rename(source, target)
+			if err != nil {
+				undoMoves(sources[:i], targets[:i])
+				log.Fatalf("fatal: renaming '%s' failed: %v", source, err)
+			}
+
+			// Index entries keep their content, only path and stat data change
+			var moved []utils.IndexEntry
+			for _, entry := range entries {
+				if isInsideFolder(entry.Path, source) {
+					entry.Path = target + strings.TrimPrefix(entry.Path, source)
+					entry = utils.RefreshEntryStat(entry)
+				} else if entry.Path == target || isInsideFolder(entry.Path, target) {
+					continue // Overwritten destination file is replaced by source
+				}
+				moved = append(moved, entry)
+			}
+			entries = moved
+		}
+
+		err = utils.WriteIndexFile(entries)
+		if err != nil {
+			undoMoves(sources, targets)
+			log.Fatalln("Error while writing index file:", err)
+		}
+	},
+}
+
+// Check source is tracked and can be moved to target, reason is returned as error otherwise
+func checkMove(source string, target string, entries []utils.IndexEntry) error {
+	sourceInfo, err := os.Lstat(source)
+	if err != nil {
+		return errors.New("bad source")
+	}
+
+	isTracked := false
+	for _, entry := range entries {
+		if isInsideFolder(entry.Path, source) {
+			if entry.Stage() != 0 {
+				return errors.New("conflicted")
+			}
+			isTracked = true
+		}
+	}
+	if !isTracked {
+		return errors.New("not under version control")
+	}
+
+	if sourceInfo.IsDir() && isInsideFolder(target, source) {
+		return errors.New("can not move directory into itself")
+	}
+	if targetInfo, err := os.Lstat(target); err == nil {
+		if sourceInfo.IsDir() || targetInfo.IsDir() || !mvForce {
+			return errors.New("destination exists")
+		}
+	}
+	if _, err := os.Stat(filepath.Dir(target)); err != nil {
+		return errors.New("destination directory does not exist")
+	}
+
+	return nil
+}
+
+// Move files back in reverse order, keeps working tree consistent with index that is not written
+func undoMoves(sources []string, targets []string) {
+	for i := len(sources) - 1; i >= 0; i-- {
+		os.Rename(targets[i], sources[i])
+	}
+}
+
+// Check path is the given folder (or file) itself or inside it
+func isInsideFolder(path string, folder string) bool {
+	return path == folder || strings.HasPrefix(path, folder+"/")
+}
+
+func init() {
+	mvCmd.Flags().BoolVarP(&mvForce, "force", "f", false, "Force move/rename even if target exists")
+	rootCmd.AddCommand(mvCmd)
+}
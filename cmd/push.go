@@ -0,0 +1,368 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Kei-K23/git-go/internal/transport"
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// Value of --force-with-lease without "=<ref>", lease protects every pushed ref
+const leaseAllRefs = "*"
+
+var pushForce bool            // Update remote refs even when it is not fast-forward
+var pushForceWithLease string // Force update only when remote ref is still at the expected value
+
+// pushCmd represents the push command
+var pushCmd = &cobra.Command{
+	Use:   "push [<remote> [<refspec>...]]",
+	Short: "Update remote refs along with associated objects",
+	Run: func(cmd *cobra.Command, args []string) {
+		remote := "origin"
+		if len(args) > 0 {
+			remote = args[0]
+		}
+
+		var specs []string
+		if len(args) > 1 {
+			specs = args[1:]
+		} else {
+			// Without refspec the current branch is pushed to branch with the same name
+			branch := utils.GerCurrentBranch()
+			if branch == "" {
+				fmt.Println("fatal: You are not currently on a branch.")
+				os.Exit(1)
+			}
+			specs = append(specs, "refs/heads/"+branch)
+		}
+
+		ok, err := pushRemote(remote, specs)
+		if err != nil {
+			fmt.Printf("fatal: %v\n", err)
+			os.Exit(1)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+// Ref update that push wants to make on remote
+type pushUpdate struct {
+	localRef  string // Empty when pushed object is given by hash value or ref is deleted
+	remoteRef string
+	oldHash   string
+	newHash   string // Empty when remote ref is deleted
+	force     bool
+	lease     *string // Value remote ref must have for forced update, nil when not protected by lease
+}
+
+// Check remote ref doesn't need update
+func (update pushUpdate) isUpToDate() bool {
+	return update.oldHash == update.newHash
+}
+
+// Send objects and ref updates to remote, false is returned when some ref is rejected
+func pushRemote(remote string, specs []string) (bool, error) {
+	url, ok := utils.GetRemoteURL(remote)
+	if !ok {
+		return false, fmt.Errorf("'%s' does not appear to be a git repository", remote)
+	}
+
+	conn, err := transport.OpenPush(url)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	adv, err := conn.Advertise()
+	if err != nil {
+		return false, err
+	}
+
+	var updates []pushUpdate
+	for _, spec := range specs {
+		update, err := parsePushRefSpec(spec, adv)
+		if err != nil {
+			return false, err
+		}
+		if pushForce {
+			update.force = true
+		}
+		if pushForceWithLease != "" {
+			update.lease, err = pushLease(remote, update.remoteRef)
+			if err != nil {
+				return false, err
+			}
+		}
+		updates = append(updates, update)
+	}
+
+	// Check every update before anything is sent, rejected updates are left out of the push
+	rejected := make(map[string]string)
+	var commands []transport.RefUpdate
+	var wants []string
+	for _, update := range updates {
+		if update.isUpToDate() {
+			continue
+		}
+		if reason := checkPushUpdate(update); reason != "" {
+			rejected[update.remoteRef] = reason
+			continue
+		}
+		commands = append(commands, transport.RefUpdate{Name: update.remoteRef, OldHash: update.oldHash, NewHash: update.newHash})
+		if update.newHash != "" {
+			wants = append(wants, update.newHash)
+		}
+	}
+
+	report := &transport.PushReport{RefErrors: make(map[string]string)}
+	if len(commands) > 0 {
+		// Remote already has history of its refs that we know about
+		var haves []string
+		for _, ref := range adv.Refs {
+			if utils.HasObject(ref.Hash) {
+				haves = append(haves, ref.Hash)
+			}
+		}
+
+		hashes, err := utils.MissingObjects(wants, haves)
+		if err != nil {
+			return false, err
+		}
+
+		options := utils.DefaultPackOptions
+		options.RefDelta = !adv.HasCapability("ofs-delta")
+		packData, _, err := utils.EncodePack(hashes, options)
+		if err != nil {
+			return false, err
+		}
+
+		report, err = conn.SendPack(adv, commands, packData)
+		if err != nil {
+			return false, err
+		}
+		if report.UnpackError != "" {
+			fmt.Printf("error: remote unpack failed: %s\n", report.UnpackError)
+		}
+	}
+
+	return printPushResult(remote, url, updates, rejected, report)
+}
+
+// Parse push refspec "[+]<src>[:<dst>]", empty source deletes destination ref
+func parsePushRefSpec(spec string, adv *transport.Advertisement) (pushUpdate, error) {
+	var update pushUpdate
+	if strings.HasPrefix(spec, "+") {
+		update.force = true
+		spec = spec[1:]
+	}
+
+	src, dst, hasDst := strings.Cut(spec, ":")
+	if !hasDst {
+		dst = src
+	}
+	if dst == "" {
+		return pushUpdate{}, fmt.Errorf("invalid refspec '%s'", spec)
+	}
+
+	if src != "" {
+		hashValue, err := utils.ResolveObjectName(src)
+		if err != nil {
+			return pushUpdate{}, fmt.Errorf("src refspec %s does not match any", src)
+		}
+		update.newHash = hashValue
+		update.localRef = localRefName(src)
+	}
+
+	update.remoteRef = remoteRefName(dst, update.localRef, adv)
+	if ref, ok := adv.Find(update.remoteRef); ok {
+		update.oldHash = ref.Hash
+	}
+	if src == "" && update.oldHash == "" {
+		return pushUpdate{}, fmt.Errorf("unable to delete '%s': remote ref does not exist", dst)
+	}
+
+	return update, nil
+}
+
+// Get full name of local ref given by short name, empty string when name is not a ref (e.g hash value)
+func localRefName(name string) string {
+	switch {
+	case strings.HasPrefix(name, "refs/") && utils.ReadRef(name) != "":
+		return name
+	case utils.BranchExists(name):
+		return "refs/heads/" + name
+	case utils.TagExists(name):
+		return "refs/tags/" + name
+	}
+	return ""
+}
+
+// Get full name of remote ref, short name is matched with remote refs first and then takes kind of local ref
+func remoteRefName(name string, localRef string, adv *transport.Advertisement) string {
+	if strings.HasPrefix(name, "refs/") {
+		return name
+	}
+	for _, prefix := range []string{"refs/heads/", "refs/tags/"} {
+		if _, ok := adv.Find(prefix + name); ok {
+			return prefix + name
+		}
+	}
+	if strings.HasPrefix(localRef, "refs/tags/") {
+		return "refs/tags/" + name
+	}
+	return "refs/heads/" + name
+}
+
+// Get value remote ref must have for --force-with-lease, it is explicit value or our remote-tracking ref
+// Missing remote-tracking ref means remote ref must not exist
+func pushLease(remote string, remoteRef string) (*string, error) {
+	ref, expected, hasExpected := strings.Cut(pushForceWithLease, ":")
+	if ref != leaseAllRefs && ref != remoteRef && ref != shortRefName(remoteRef) {
+		return nil, nil
+	}
+
+	if hasExpected {
+		hashValue, err := utils.ResolveObjectName(expected)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse expected object name '%s'", expected)
+		}
+		return &hashValue, nil
+	}
+
+	hashValue := utils.ReadRef(remoteTrackingRef(remote, remoteRef))
+	return &hashValue, nil
+}
+
+// Map remote ref to remote-tracking ref by fetch refspecs of remote, empty string when it is not fetched
+func remoteTrackingRef(remote string, remoteRef string) string {
+	refSpecs, err := utils.GetRemoteFetchSpecs(remote)
+	if err != nil {
+		return ""
+	}
+	for _, refSpec := range refSpecs {
+		if localRef, ok := refSpec.MapSource(remoteRef); ok {
+			return localRef
+		}
+	}
+	return ""
+}
+
+// Check update is allowed, reason of rejection is returned otherwise
+func checkPushUpdate(update pushUpdate) string {
+	if update.lease != nil {
+		if update.oldHash != *update.lease {
+			return "stale info"
+		}
+		return "" // Remote ref is what we expect, so it can be overwritten
+	}
+
+	if update.oldHash == "" || update.newHash == "" || update.force {
+		return ""
+	}
+	if strings.HasPrefix(update.remoteRef, "refs/tags/") {
+		return "already exists"
+	}
+
+	// Remote ref must be part of history we push, we can't know it when we don't have its commit
+	if !utils.HasObject(update.oldHash) {
+		return "fetch first"
+	}
+	isFastForward, err := utils.IsAncestor(update.oldHash, update.newHash)
+	if err != nil || !isFastForward {
+		return "non-fast-forward"
+	}
+
+	return ""
+}
+
+// Print result of every update in the same format as Git and update remote-tracking refs of pushed refs
+func printPushResult(remote string, url string, updates []pushUpdate, rejected map[string]string, report *transport.PushReport) (bool, error) {
+	success := true
+	printedURL := false
+
+	for _, update := range updates {
+		if update.isUpToDate() {
+			continue
+		}
+		if !printedURL {
+			fmt.Printf("To %s\n", url)
+			printedURL = true
+		}
+
+		src, dst := shortRefName(update.localRef), shortRefName(update.remoteRef)
+		if update.localRef == "" && update.newHash != "" {
+			src = update.newHash[:7]
+		}
+
+		if reason, ok := rejected[update.remoteRef]; ok {
+			fmt.Printf(" ! %-17s %s -> %s (%s)\n", "[rejected]", src, dst, reason)
+			success = false
+			continue
+		}
+		if reason, ok := report.RefErrors[update.remoteRef]; ok {
+			fmt.Printf(" ! %-17s %s -> %s (%s)\n", "[remote rejected]", src, dst, reason)
+			success = false
+			continue
+		}
+
+		switch {
+		case update.newHash == "":
+			fmt.Printf(" - %-17s %s\n", "[deleted]", dst)
+		case update.oldHash == "" && strings.HasPrefix(update.remoteRef, "refs/tags/"):
+			fmt.Printf(" * %-17s %s -> %s\n", "[new tag]", src, dst)
+		case update.oldHash == "":
+			fmt.Printf(" * %-17s %s -> %s\n", "[new branch]", src, dst)
+		default:
+			isFastForward, _ := utils.IsAncestor(update.oldHash, update.newHash)
+			if isFastForward {
+				fmt.Printf("   %-17s %s -> %s\n", update.oldHash[:7]+".."+update.newHash[:7], src, dst)
+			} else {
+				fmt.Printf(" + %-17s %s -> %s (forced update)\n", update.oldHash[:7]+"..."+update.newHash[:7], src, dst)
+			}
+		}
+
+		// Remote-tracking ref follows pushed ref, so next push and fetch know its value
+		if trackingRef := remoteTrackingRef(remote, update.remoteRef); trackingRef != "" {
+			var err error
+			if update.newHash == "" {
+				err = utils.DeleteRef(trackingRef)
+			} else {
+				err = utils.UpdateRef(trackingRef, update.newHash, "update by push")
+			}
+			if err != nil && !os.IsNotExist(err) {
+				return false, err
+			}
+		}
+	}
+
+	if !printedURL {
+		fmt.Println("Everything up-to-date")
+	}
+	if !success {
+		fmt.Printf("error: failed to push some refs to '%s'\n", url)
+		for _, reason := range rejected {
+			if reason == "non-fast-forward" || reason == "fetch first" {
+				fmt.Println("hint: Updates were rejected because the remote contains work that you do not have locally.")
+				fmt.Println("hint: Fetch and merge the remote changes before pushing again, or use --force.")
+				break
+			}
+		}
+	}
+
+	return success, nil
+}
+
+func init() {
+	pushCmd.Flags().BoolVarP(&pushForce, "force", "f", false, "Update remote refs even when it is not fast-forward")
+	pushCmd.Flags().StringVar(&pushForceWithLease, "force-with-lease", "", "Force update only when remote ref is at the expected value (<ref>[:<expect>])")
+	pushCmd.Flags().Lookup("force-with-lease").NoOptDefVal = leaseAllRefs
+	rootCmd.AddCommand(pushCmd)
+}
@@ -0,0 +1,584 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// Folder inside repository folder that keeps state of rebase in progress
+const rebaseStateDir = "rebase-merge"
+
+var rebaseInteractive bool // Edit list of commits before replaying them
+var rebaseContinue bool    // Continue after conflicts are resolved or commit is edited
+var rebaseAbort bool       // Go back to the state before rebase started
+var rebaseSkip bool        // Drop the commit that stopped rebase and continue
+
+// Single line of rebase todo list (e.g "pick <commit> <subject>")
+type rebaseStep struct {
+	command string
+	hash    string
+	subject string
+}
+
+// Full names of todo commands by their short forms
+var rebaseCommands = map[string]string{
+	"p": "pick", "pick": "pick",
+	"r": "reword", "reword": "reword",
+	"e": "edit", "edit": "edit",
+	"s": "squash", "squash": "squash",
+	"f": "fixup", "fixup": "fixup",
+	"d": "drop", "drop": "drop",
+}
+
+// rebaseCmd represents the rebase command
+var rebaseCmd = &cobra.Command{
+	Use:   "rebase [-i] <upstream> | --continue | --abort | --skip",
+	Short: "Reapply commits on top of another base tip",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		actionCount := 0
+		for _, isSet := range []bool{rebaseContinue, rebaseAbort, rebaseSkip} {
+			if isSet {
+				actionCount++
+			}
+		}
+		if actionCount > 1 || (actionCount == 1 && (len(args) > 0 || rebaseInteractive)) {
+			fmt.Println("fatal: --continue, --abort and --skip can't be combined with other options")
+			os.Exit(1)
+		}
+
+		_, inProgress := readRebaseState("head-name")
+		if actionCount == 1 && !inProgress {
+			fmt.Println("fatal: No rebase in progress?")
+			os.Exit(1)
+		}
+
+		switch {
+		case rebaseContinue:
+			continueRebase()
+		case rebaseAbort:
+			abortRebase()
+		case rebaseSkip:
+			err := utils.CheckoutCommit(utils.GetCurrentCommit(), true)
+			if err != nil {
+				log.Fatalln("Error while resetting working tree:", err)
+			}
+			// Step that failed before it was applied is still at the head of todo
+			_, isStopped := readRebaseState("stopped-sha")
+			_, isEditing := readRebaseState("amend")
+			if todo := readRebaseSteps("git-rebase-todo"); !isStopped && !isEditing && len(todo) > 0 {
+				markRebaseStepDone(todo)
+			}
+			utils.RemoveStateFiles(rebaseStatePath("stopped-sha"), rebaseStatePath("message"), rebaseStatePath("amend"))
+			runRebase()
+		default:
+			if inProgress {
+				fmt.Printf("fatal: It seems that there is already a %s directory, run \"git-go rebase --continue\", \"--skip\" or \"--abort\".\n", rebaseStateDir)
+				os.Exit(1)
+			}
+			if len(args) == 0 {
+				fmt.Println("fatal: no upstream given")
+				os.Exit(1)
+			}
+			startRebase(args[0])
+		}
+	},
+}
+
+// Get path of rebase state file relative to repository folder
+func rebaseStatePath(name string) string {
+	return rebaseStateDir + "/" + name
+}
+
+// Read rebase state file without surrounding spaces, false is returned when it doesn't exist
+func readRebaseState(name string) (string, bool) {
+	content, ok := utils.ReadStateFile(rebaseStatePath(name))
+	return strings.TrimSpace(content), ok
+}
+
+// Write rebase state file, exit when it can't be written
+func writeRebaseState(name string, content string) {
+	err := utils.WriteStateFile(rebaseStatePath(name), content)
+	if err != nil {
+		log.Fatalln("Error while saving rebase state:", err)
+	}
+}
+
+// Format todo steps as todo file lines
+func formatRebaseSteps(steps []rebaseStep, shortHash bool) string {
+	var sb strings.Builder
+	for _, step := range steps {
+		hashValue := step.hash
+		if shortHash {
+			hashValue = hashValue[:7]
+		}
+		fmt.Fprintf(&sb, "%s %s %s\n", step.command, hashValue, step.subject)
+	}
+	return sb.String()
+}
+
+// Parse todo file lines, blank lines and comments are skipped and commits are resolved to full hash values
+func parseRebaseSteps(content string) ([]rebaseStep, error) {
+	var steps []rebaseStep
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 3)
+		command, ok := rebaseCommands[fields[0]]
+		if !ok || len(fields) < 2 {
+			return nil, fmt.Errorf("invalid line %d: %s", i+1, line)
+		}
+		hashValue, err := utils.ResolveRevision(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %d: %s", i+1, line)
+		}
+
+		step := rebaseStep{command: command, hash: hashValue}
+		if len(fields) == 3 {
+			step.subject = fields[2]
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// Read rebase state file holding todo steps
+func readRebaseSteps(name string) []rebaseStep {
+	content, _ := readRebaseState(name)
+	steps, err := parseRebaseSteps(content)
+	if err != nil {
+		log.Fatalln("Error while reading rebase todo list:", err)
+	}
+	return steps
+}
+
+// Save rebase state, let user edit todo list with -i and detach HEAD at upstream before commits are replayed
+func startRebase(upstream string) {
+	localChanges, err := utils.LocalChanges()
+	if err != nil {
+		log.Fatalln("Error while reading index file:", err)
+	}
+	if len(localChanges) > 0 {
+		fmt.Println("error: cannot rebase: Your index or working tree contains uncommitted changes.")
+		fmt.Println("error: Please commit or stash them.")
+		os.Exit(1)
+	}
+
+	onto, err := utils.ResolveRevision(upstream)
+	if err != nil {
+		fmt.Printf("fatal: invalid upstream '%s'\n", upstream)
+		os.Exit(1)
+	}
+	origHead := utils.GetCurrentCommit()
+	if origHead == "" {
+		fmt.Println("fatal: HEAD does not point to any commit yet")
+		os.Exit(1)
+	}
+
+	headName := "detached HEAD"
+	if branch := utils.GerCurrentBranch(); branch != "" {
+		headName = "refs/heads/" + branch
+	}
+
+	// Nothing to replay when upstream is already in history of HEAD
+	isUpToDate, err := utils.IsAncestor(onto, origHead)
+	if err != nil {
+		log.Fatalln("Error while reading commit history:", err)
+	}
+	if isUpToDate && !rebaseInteractive {
+		fmt.Printf("Current branch %s is up to date.\n", strings.TrimPrefix(headName, "refs/heads/"))
+		return
+	}
+
+	commits, err := utils.CommitsBetween(onto, origHead)
+	if err != nil {
+		log.Fatalln("Error while reading commit history:", err)
+	}
+	var steps []rebaseStep
+	for _, commit := range commits {
+		if len(commit.Parents) > 1 {
+			continue // Merge commits are not replayed
+		}
+		steps = append(steps, rebaseStep{command: "pick", hash: commit.Hash, subject: commit.Subject()})
+	}
+
+	writeRebaseState("head-name", headName+"\n")
+	writeRebaseState("onto", onto+"\n")
+	writeRebaseState("orig-head", origHead+"\n")
+	writeRebaseState("done", "")
+
+	if rebaseInteractive {
+		writeRebaseState("interactive", "")
+		steps = editRebaseSteps(steps, onto, origHead)
+	}
+	writeRebaseState("git-rebase-todo", formatRebaseSteps(steps, false))
+
+	err = utils.CheckoutCommit(onto, false)
+	if err != nil {
+		utils.RemoveStateDir(rebaseStateDir)
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+	err = utils.SetDetachedHead(onto, "rebase (start): checkout "+upstream)
+	if err != nil {
+		log.Fatalln("Error while updating HEAD file:", err)
+	}
+
+	runRebase()
+}
+
+// Open todo list in editor, rebase is aborted when every line is removed or a line is invalid
+func editRebaseSteps(steps []rebaseStep, onto string, origHead string) []rebaseStep {
+	var sb strings.Builder
+	sb.WriteString(formatRebaseSteps(steps, true))
+	fmt.Fprintf(&sb, `
+# Rebase %s..%s onto %s (%d commands)
+#
+# Commands:
+# p, pick <commit> = use commit
+# r, reword <commit> = use commit, but edit the commit message
+# e, edit <commit> = use commit, but stop for amending
+# s, squash <commit> = use commit, but meld into previous commit
+# f, fixup <commit> = like "squash" but keep only the previous commit's log message
+# d, drop <commit> = remove commit
+#
+# These lines can be re-ordered; they are executed from top to bottom.
+#
+# If you remove a line here THAT COMMIT WILL BE LOST.
+#
+# However, if you remove everything, the rebase will be aborted.
+#
+`, onto[:7], origHead[:7], onto[:7], len(steps))
+	writeRebaseState("git-rebase-todo", sb.String())
+
+	err := utils.EditFile(utils.GitPath(rebaseStateDir, "git-rebase-todo"))
+	if err != nil {
+		utils.RemoveStateDir(rebaseStateDir)
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+
+	content, _ := readRebaseState("git-rebase-todo")
+	editedSteps, err := parseRebaseSteps(content)
+	if err != nil {
+		utils.RemoveStateDir(rebaseStateDir)
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(editedSteps) == 0 {
+		utils.RemoveStateDir(rebaseStateDir)
+		fmt.Println("error: nothing to do")
+		os.Exit(1)
+	}
+	return editedSteps
+}
+
+// Replay remaining todo steps one at a time, state is saved before each step so rebase can stop at any point
+func runRebase() {
+	for {
+		todo := readRebaseSteps("git-rebase-todo")
+		if len(todo) == 0 {
+			finishRebase()
+			return
+		}
+
+		// Step stays at the head of todo until it is applied (or stopped with conflicts), so --continue retries it after failure
+		step := todo[0]
+		if step.command == "drop" {
+			markRebaseStepDone(todo)
+			continue
+		}
+
+		commit, err := utils.ReadCommit(step.hash)
+		if err != nil {
+			log.Fatalln("Error when reading commit object:", err)
+		}
+
+		isFixup := step.command == "squash" || step.command == "fixup"
+		if isFixup && !hasRebasedCommit() {
+			fmt.Printf("error: cannot '%s' without a previous commit\n", step.command)
+			os.Exit(1)
+		}
+
+		// Commit whose parent is already HEAD is reused as it is
+		headCommit := utils.GetCurrentCommit()
+		if step.command == "pick" || step.command == "edit" {
+			if len(commit.Parents) == 1 && commit.Parents[0] == headCommit {
+				err = utils.CheckoutCommit(commit.Hash, false)
+				if err != nil {
+					fmt.Printf("error: %v\n", err)
+					fmt.Printf("Could not apply %s... %s\n", commit.Hash[:7], commit.Subject())
+					os.Exit(1)
+				}
+				markRebaseStepDone(todo)
+				utils.UpdateCommitHashValue(commit.Hash, fmt.Sprintf("rebase (%s): %s", step.command, commit.Subject()))
+				if step.command == "edit" {
+					stopForEdit(commit)
+				}
+				continue
+			}
+		}
+
+		message := strings.TrimSpace(commit.Message)
+		if isFixup {
+			message = squashMessage(headCommit, commit, step.command == "fixup")
+		}
+
+		result, err := utils.ApplyCommitChanges(commit, false)
+		if err != nil {
+			// Nothing was applied, so there is no stopped step to commit on --continue
+			utils.RemoveStateFiles(rebaseStatePath("stopped-sha"), rebaseStatePath("message"))
+			fmt.Printf("error: %v\n", err)
+			fmt.Printf("Could not apply %s... %s\n", commit.Hash[:7], commit.Subject())
+			os.Exit(1)
+		}
+
+		markRebaseStepDone(todo)
+		if len(result.Conflicts) > 0 {
+			stopForConflicts(commit, message, result.Conflicts)
+		}
+
+		if step.command == "reword" || step.command == "squash" {
+			message = editCommitMessage(message)
+		}
+		commitRebaseStep(step, commit, message)
+
+		if step.command == "edit" {
+			stopForEdit(commit)
+		}
+	}
+}
+
+// Move first todo step to done steps
+func markRebaseStepDone(todo []rebaseStep) {
+	done := append(readRebaseSteps("done"), todo[0])
+	writeRebaseState("git-rebase-todo", formatRebaseSteps(todo[1:], false))
+	writeRebaseState("done", formatRebaseSteps(done, false))
+}
+
+// Check rebase created a commit that squash or fixup can be melded into
+// HEAD stays at onto until then, since dropped steps and picks that became empty don't commit anything
+func hasRebasedCommit() bool {
+	onto, _ := readRebaseState("onto")
+	return utils.GetCurrentCommit() != onto
+}
+
+// Combine message of HEAD commit with message of squashed commit, fixup keeps only HEAD message
+func squashMessage(headCommit string, commit utils.Commit, isFixup bool) string {
+	head, err := utils.ReadCommit(headCommit)
+	if err != nil {
+		log.Fatalln("Error when reading commit object:", err)
+	}
+	if isFixup {
+		return strings.TrimSpace(head.Message)
+	}
+
+	return fmt.Sprintf("# This is a combination of 2 commits.\n# This is the 1st commit message:\n\n%s\n\n# This is the commit message #2:\n\n%s",
+		strings.TrimSpace(head.Message), strings.TrimSpace(commit.Message))
+}
+
+// Open commit message in editor, exit when edited message is empty
+func editCommitMessage(message string) string {
+	err := utils.WriteStateFile("COMMIT_EDITMSG", message+"\n")
+	if err != nil {
+		log.Fatalln("Error while writing commit message:", err)
+	}
+	err = utils.EditFile(utils.GitPath("COMMIT_EDITMSG"))
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+
+	content, _ := utils.ReadStateFile("COMMIT_EDITMSG")
+	edited := utils.CleanupMessage(content)
+	if edited == "" {
+		fmt.Println("Aborting commit due to empty commit message.")
+		os.Exit(1)
+	}
+	return edited
+}
+
+// Create commit from index for replayed step, squash and fixup replace HEAD commit instead of adding new one
+// Pick that has no changes left (they are already in upstream) doesn't create a commit
+func commitRebaseStep(step rebaseStep, commit utils.Commit, message string) {
+	entries, err := utils.ReadIndexFile()
+	if err != nil {
+		log.Fatalln("Error while reading index file")
+	}
+	treeHash, err := utils.WriteTree(entries)
+	if err != nil {
+		log.Fatalln("Error when creating tree object:", err)
+	}
+
+	headCommit := utils.GetCurrentCommit()
+	parents := []string{headCommit}
+	author := commit.Author
+	if step.command == "squash" || step.command == "fixup" {
+		head, err := utils.ReadCommit(headCommit)
+		if err != nil {
+			log.Fatalln("Error when reading commit object:", err)
+		}
+		parents, author = head.Parents, head.Author
+	} else if utils.GetCommitTreeHash(headCommit) == treeHash {
+		return
+	}
+
+	committer, err := utils.GetUserSignature()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	newCommit := utils.Commit{Tree: treeHash, Parents: parents, Author: author, Committer: committer, Message: message}
+	hashValue, err := utils.WriteCommit(newCommit)
+	if err != nil {
+		log.Fatalln("Error when creating commit object:", err)
+	}
+	utils.UpdateCommitHashValue(hashValue, fmt.Sprintf("rebase (%s): %s", step.command, newCommit.Subject()))
+}
+
+// Save state of conflicted step and exit, rebase --continue commits it after conflicts are resolved
+func stopForConflicts(commit utils.Commit, message string, conflicts []string) {
+	writeRebaseState("stopped-sha", commit.Hash+"\n")
+	writeRebaseState("message", message+"\n")
+
+	for _, path := range conflicts {
+		fmt.Printf("CONFLICT (content): Merge conflict in %s\n", path)
+	}
+	fmt.Printf("error: could not apply %s... %s\n", commit.Hash[:7], commit.Subject())
+	fmt.Println("hint: Resolve all conflicts manually, mark them as resolved with")
+	fmt.Println("hint: \"git-go add <conflicted_files>\", then run \"git-go rebase --continue\".")
+	fmt.Println("hint: You can instead skip this commit: run \"git-go rebase --skip\".")
+	fmt.Println("hint: To abort and get back to the state before \"git-go rebase\", run \"git-go rebase --abort\".")
+	fmt.Printf("Could not apply %s... %s\n", commit.Hash[:7], commit.Subject())
+	os.Exit(1)
+}
+
+// Stop after edit step is committed so user can amend it or add more commits
+func stopForEdit(commit utils.Commit) {
+	writeRebaseState("amend", utils.GetCurrentCommit()+"\n")
+
+	fmt.Printf("Stopped at %s...  %s\n", commit.Hash[:7], commit.Subject())
+	fmt.Println("You can amend the commit now, with")
+	fmt.Println()
+	fmt.Println("  git-go commit --amend")
+	fmt.Println()
+	fmt.Println("Once you are satisfied with your changes, run")
+	fmt.Println()
+	fmt.Println("  git-go rebase --continue")
+	os.Exit(0)
+}
+
+// Commit resolved step (or check edited commit is finished) and replay remaining steps
+func continueRebase() {
+	entries, err := utils.ReadIndexFile()
+	if err != nil {
+		log.Fatalln("Error while reading index file")
+	}
+	if unmergedPaths := utils.UnmergedPaths(entries); len(unmergedPaths) > 0 {
+		fmt.Println("error: Committing is not possible because you have unmerged files.")
+		for _, path := range unmergedPaths {
+			fmt.Printf("\t%s\n", path)
+		}
+		fmt.Println("hint: Fix them up in the work tree, and then use 'git-go add <file>' as appropriate to mark resolution.")
+		os.Exit(1)
+	}
+
+	if _, isEditing := readRebaseState("amend"); isEditing {
+		localChanges, err := utils.LocalChanges()
+		if err != nil {
+			log.Fatalln("Error while reading index file:", err)
+		}
+		if len(localChanges) > 0 {
+			fmt.Println("error: You have uncommitted changes in your working tree. Please commit them first and then run 'git-go rebase --continue' again.")
+			os.Exit(1)
+		}
+		utils.RemoveStateFiles(rebaseStatePath("amend"))
+	}
+
+	if stoppedHash, isStopped := readRebaseState("stopped-sha"); isStopped {
+		done := readRebaseSteps("done")
+		step := done[len(done)-1]
+		commit, err := utils.ReadCommit(stoppedHash)
+		if err != nil {
+			log.Fatalln("Error when reading commit object:", err)
+		}
+
+		message, _ := readRebaseState("message")
+		if step.command == "reword" || step.command == "squash" {
+			message = editCommitMessage(message)
+		}
+		commitRebaseStep(step, commit, message)
+		utils.RemoveStateFiles(rebaseStatePath("stopped-sha"), rebaseStatePath("message"))
+	}
+
+	runRebase()
+}
+
+// Restore working tree, index and HEAD to the state before rebase started
+func abortRebase() {
+	headName, _ := readRebaseState("head-name")
+	origHead, _ := readRebaseState("orig-head")
+
+	err := utils.CheckoutCommit(origHead, true)
+	if err != nil {
+		log.Fatalln("Error while resetting working tree:", err)
+	}
+
+	message := "rebase (abort): returning to " + headName
+	if branch, ok := strings.CutPrefix(headName, "refs/heads/"); ok {
+		err = utils.SetHeadToBranch(branch, message)
+	} else {
+		err = utils.SetDetachedHead(origHead, message)
+	}
+	if err != nil {
+		log.Fatalln("Error while updating HEAD file:", err)
+	}
+
+	err = utils.RemoveStateDir(rebaseStateDir)
+	if err != nil {
+		log.Fatalln("Error while removing rebase state:", err)
+	}
+}
+
+// Move rebased branch to replayed commits and make HEAD point to it again
+func finishRebase() {
+	headName, _ := readRebaseState("head-name")
+	onto, _ := readRebaseState("onto")
+	newHead := utils.GetCurrentCommit()
+
+	if branch, ok := strings.CutPrefix(headName, "refs/heads/"); ok {
+		err := utils.UpdateRef(headName, newHead, fmt.Sprintf("rebase (finish): %s onto %s", headName, onto))
+		if err == nil {
+			err = utils.SetHeadToBranch(branch, "rebase (finish): returning to "+headName)
+		}
+		if err != nil {
+			log.Fatalln("Error while updating branch:", err)
+		}
+	}
+
+	err := utils.RemoveStateDir(rebaseStateDir)
+	if err != nil {
+		log.Fatalln("Error while removing rebase state:", err)
+	}
+	fmt.Printf("Successfully rebased and updated %s.\n", headName)
+}
+
+func init() {
+	rebaseCmd.Flags().BoolVarP(&rebaseInteractive, "interactive", "i", false, "Let the user edit the list of commits to rebase")
+	rebaseCmd.Flags().BoolVar(&rebaseContinue, "continue", false, "Continue the rebasing process after resolving a conflict")
+	rebaseCmd.Flags().BoolVar(&rebaseAbort, "abort", false, "Abort the rebase operation and reset HEAD to the original branch")
+	rebaseCmd.Flags().BoolVar(&rebaseSkip, "skip", false, "Restart the rebasing process by skipping the current patch")
+	rootCmd.AddCommand(rebaseCmd)
+}
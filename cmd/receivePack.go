@@ -0,0 +1,124 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Kei-K23/git-go/internal/transport"
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// receivePackCmd represents the receive-pack command
+var receivePackCmd = &cobra.Command{
+	Use:   "receive-pack <directory>",
+	Short: "Receive what is pushed into the repository",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Stdout is used by protocol, so every message goes to stderr
+		gitDir, err := utils.FindLocalRepository(args[0])
+		if err != nil {
+			log.Fatalf("fatal: %v", err)
+		}
+		utils.SetGitDir(gitDir)
+
+		in := bufio.NewReader(os.Stdin)
+		out := os.Stdout
+
+		var refs []transport.Ref
+		for _, name := range utils.ListAllRefs() {
+			refs = append(refs, transport.Ref{Name: name, Hash: utils.ReadRef(name)})
+		}
+		capabilities := []string{"report-status", "delete-refs", "ofs-delta", "agent=" + transport.Agent}
+
+		err = transport.WriteAdvertisement(out, refs, capabilities)
+		if err != nil {
+			log.Fatalln("fatal: cannot write ref advertisement:", err)
+		}
+
+		updates, clientCapabilities, err := transport.ReadUpdateCommands(in)
+		if err != nil {
+			log.Fatalf("fatal: %v", err)
+		}
+		if len(updates) == 0 {
+			return // Client has nothing to push
+		}
+
+		report := &transport.PushReport{RefErrors: make(map[string]string)}
+
+		// Pack is only sent when some ref is created or updated
+		if slices.ContainsFunc(updates, func(update transport.RefUpdate) bool { return !update.IsDelete() }) {
+			packData, err := utils.ReadPackStream(in)
+			if err == nil {
+				_, err = utils.StorePack(packData)
+			}
+			if err != nil {
+				report.UnpackError = err.Error()
+			}
+		}
+
+		for _, update := range updates {
+			if reason := receiveRefUpdate(update, report.UnpackError != ""); reason != "" {
+				report.RefErrors[update.Name] = reason
+			}
+		}
+
+		if slices.Contains(clientCapabilities, "report-status") {
+			err = transport.WritePushReport(out, report, updates)
+			if err != nil {
+				log.Fatalln("fatal: cannot write report:", err)
+			}
+		}
+	},
+}
+
+// Apply one pushed ref update, reason is returned when ref is not updated
+func receiveRefUpdate(update transport.RefUpdate, unpackFailed bool) string {
+	if unpackFailed {
+		return "unpacker error"
+	}
+	if !strings.HasPrefix(update.Name, "refs/") || utils.ValidateRefName(update.Name) != nil {
+		return "funny refname"
+	}
+
+	// Ref must not be changed by someone else after it was advertised
+	if utils.ReadRef(update.Name) != update.OldHash {
+		return "failed to update ref"
+	}
+
+	// Working tree and index of repository would not match its branch anymore (bare repository has no working tree)
+	isBare := filepath.Base(utils.GitDir()) != utils.GitDirName
+	if !isBare && update.Name == "refs/heads/"+utils.GerCurrentBranch() {
+		if update.IsDelete() {
+			return "deletion of the current branch prohibited"
+		}
+		return "branch is currently checked out"
+	}
+
+	if update.IsDelete() {
+		if err := utils.DeleteRef(update.Name); err != nil {
+			return "failed to delete"
+		}
+		return ""
+	}
+
+	if !utils.HasObject(update.NewHash) {
+		return "missing necessary objects"
+	}
+	if err := utils.UpdateRef(update.Name, update.NewHash, "push"); err != nil {
+		return "failed to write"
+	}
+
+	return ""
+}
+
+func init() {
+	rootCmd.AddCommand(receivePackCmd)
+}
@@ -0,0 +1,225 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var reflogExpire string // Entries older than this time are removed by expire
+var reflogAll bool      // Expire reflog of every reference
+
+// reflogCmd represents the reflog command
+var reflogCmd = &cobra.Command{
+	Use:   "reflog [show [<ref>] | expire [--expire=<time>] [--all] [<ref>...] | delete <ref>@{<n>}...]",
+	Short: "Manage reflog information",
+	Run: func(cmd *cobra.Command, args []string) {
+		subcommand := "show"
+		if len(args) > 0 && (args[0] == "show" || args[0] == "expire" || args[0] == "delete") {
+			subcommand, args = args[0], args[1:]
+		}
+
+		switch subcommand {
+		case "show":
+			showReflog(args)
+		case "expire":
+			expireReflogs(args)
+		case "delete":
+			deleteReflogEntries(args)
+		}
+	},
+}
+
+// Print entries of reflog newest first, HEAD reflog is shown by default
+func showReflog(args []string) {
+	if len(args) > 1 {
+		fmt.Println("fatal: too many arguments")
+		os.Exit(1)
+	}
+
+	name := "HEAD"
+	if len(args) == 1 {
+		name = args[0]
+	}
+
+	fullName, err := utils.ReflogRefName(name)
+	if err != nil {
+		fmt.Printf("fatal: ambiguous argument '%s': %v\n", name, err)
+		os.Exit(1)
+	}
+
+	entries, err := utils.ReadReflog(fullName)
+	if err != nil {
+		log.Fatalln("Error while reading reflog:", err)
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		hashValue := entries[i].NewHash
+		if hashValue == "" {
+			hashValue = strings.Repeat("0", 40)
+		}
+		fmt.Printf("%s %s@{%d}: %s\n", hashValue[:7], name, len(entries)-1-i, entries[i].Message)
+	}
+}
+
+// Remove entries older than --expire time (90 days by default) from reflogs of given references
+func expireReflogs(args []string) {
+	cutoff, err := parseExpireTime(reflogExpire)
+	if err != nil {
+		fmt.Printf("fatal: %v\n", err)
+		os.Exit(1)
+	}
+
+	var names []string
+	if reflogAll {
+		names = utils.ListReflogs()
+	}
+	for _, arg := range args {
+		fullName, err := utils.ReflogRefName(arg)
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+			os.Exit(1)
+		}
+		names = append(names, fullName)
+	}
+	if len(names) == 0 {
+		fmt.Println("fatal: no reflog specified to expire")
+		os.Exit(1)
+	}
+
+	for _, name := range names {
+		entries, err := utils.ReadReflog(name)
+		if err != nil {
+			log.Fatalf("Error while reading reflog of '%s': %v", name, err)
+		}
+
+		var kept []utils.ReflogEntry
+		for _, entry := range entries {
+			if entry.Committer.When.After(cutoff) {
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) == len(entries) {
+			continue
+		}
+
+		err = utils.WriteReflog(name, kept)
+		if err != nil {
+			log.Fatalf("Error while writing reflog of '%s': %v", name, err)
+		}
+	}
+}
+
+// Remove single entries selected by "<ref>@{<n>}" from reflog
+func deleteReflogEntries(args []string) {
+	if len(args) == 0 {
+		fmt.Println("fatal: no reflog entry specified to delete")
+		os.Exit(1)
+	}
+
+	// Entries of the same reflog are removed together, so numbers given later still refer to original entries
+	toDelete := make(map[string]map[int]bool)
+	var names []string
+	for _, arg := range args {
+		ref, number, ok := utils.ParseReflogSelector(arg)
+		if !ok {
+			fmt.Printf("fatal: not a reflog: %s\n", arg)
+			os.Exit(1)
+		}
+		fullName, err := utils.ReflogRefName(ref)
+		if err != nil {
+			fmt.Printf("fatal: %v\n", err)
+			os.Exit(1)
+		}
+		if toDelete[fullName] == nil {
+			toDelete[fullName] = make(map[int]bool)
+			names = append(names, fullName)
+		}
+		toDelete[fullName][number] = true
+	}
+
+	for _, name := range names {
+		entries, err := utils.ReadReflog(name)
+		if err != nil {
+			log.Fatalf("Error while reading reflog of '%s': %v", name, err)
+		}
+
+		var kept []utils.ReflogEntry
+		for i, entry := range entries {
+			if !toDelete[name][len(entries)-1-i] {
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) == len(entries) {
+			fmt.Printf("error: no reflog entry to delete in '%s'\n", name)
+			os.Exit(1)
+		}
+
+		err = utils.WriteReflog(name, kept)
+		if err != nil {
+			log.Fatalf("Error while writing reflog of '%s': %v", name, err)
+		}
+	}
+}
+
+// Parse expire time, "now" and "all" expire every entry, "never" keeps every entry
+// Relative time is "<n>.<unit>[.ago]" or "<n> <unit> ago" (e.g 90.days.ago, 2 weeks ago)
+func parseExpireTime(value string) (time.Time, error) {
+	now := time.Now()
+	switch value {
+	case "now", "all":
+		return now.Add(time.Second), nil
+	case "never", "false":
+		return time.Time{}, nil
+	}
+
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	if date, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return date, nil
+	}
+
+	fields := strings.FieldsFunc(value, func(r rune) bool { return r == '.' || r == ' ' })
+	if len(fields) == 3 && fields[2] == "ago" {
+		fields = fields[:2]
+	}
+	if len(fields) == 2 {
+		amount, err := strconv.Atoi(fields[0])
+		if err == nil && amount >= 0 {
+			switch strings.TrimSuffix(fields[1], "s") {
+			case "second":
+				return now.Add(-time.Duration(amount) * time.Second), nil
+			case "minute":
+				return now.Add(-time.Duration(amount) * time.Minute), nil
+			case "hour":
+				return now.Add(-time.Duration(amount) * time.Hour), nil
+			case "day":
+				return now.AddDate(0, 0, -amount), nil
+			case "week":
+				return now.AddDate(0, 0, -7*amount), nil
+			case "month":
+				return now.AddDate(0, -amount, 0), nil
+			case "year":
+				return now.AddDate(-amount, 0, 0), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("malformed expiration date '%s'", value)
+}
+
+func init() {
+	reflogCmd.Flags().StringVar(&reflogExpire, "expire", "90.days.ago", "Prune entries older than the specified time")
+	reflogCmd.Flags().BoolVar(&reflogAll, "all", false, "Process the reflogs of all references")
+	rootCmd.AddCommand(reflogCmd)
+}
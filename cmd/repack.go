@@ -0,0 +1,103 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var repackAll bool    // variable to store -a flag to pack every object into single pack
+var repackDelete bool // variable to store -d flag to remove redundant packs and loose objects
+var repackWindow int  // variable to store number of objects tried as delta base
+var repackDepth int   // variable to store maximum delta chain length
+
+// repackCmd represents the repack command
+var repackCmd = &cobra.Command{
+	Use:   "repack [-a] [-d]",
+	Short: "Pack unpacked objects in a repository",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		packName, count, _, err := repackObjects(repackAll, repackDelete, utils.PackOptions{Window: repackWindow, MaxDepth: repackDepth})
+		if err != nil {
+			log.Fatalln("Error while packing objects:", err)
+		}
+
+		if packName == "" {
+			fmt.Println("Nothing new to pack.")
+			return
+		}
+		fmt.Printf("Packed %d objects into %s\n", count, packName)
+	},
+}
+
+// Write loose objects (or every object with all) into new pack, then optionally remove objects that became redundant
+// Pack name, number of packed objects and number of pruned loose objects are returned, empty pack name means there was nothing to pack
+func repackObjects(all bool, deleteRedundant bool, options utils.PackOptions) (string, int, int, error) {
+	looseObjects, err := utils.ListLooseObjects()
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	oldPacks := utils.Packs()
+
+	var hashes []string
+	if all {
+		seen := make(map[string]bool)
+		for _, hashValue := range append(utils.ListPackedObjects(), looseObjects...) {
+			if !seen[hashValue] {
+				seen[hashValue] = true
+				hashes = append(hashes, hashValue)
+			}
+		}
+	} else {
+		// Objects that are already packed don't need to be packed again
+		for _, hashValue := range looseObjects {
+			if !utils.IsPackedObject(hashValue) {
+				hashes = append(hashes, hashValue)
+			}
+		}
+	}
+
+	packName, pruned := "", 0
+	if len(hashes) > 0 {
+		packName, err = utils.WritePack(hashes, options)
+		if err != nil {
+			return "", 0, 0, err
+		}
+	}
+
+	if deleteRedundant {
+		// With -a every object is inside new pack, so old packs are redundant
+		if all && packName != "" {
+			for _, oldPack := range oldPacks {
+				if oldPack.Name() == packName {
+					continue
+				}
+				err = utils.RemovePack(oldPack.Name())
+				if err != nil {
+					return "", 0, 0, err
+				}
+			}
+		}
+
+		pruned, err = utils.PruneLooseObjects()
+		if err != nil {
+			return "", 0, 0, err
+		}
+	}
+
+	return packName, len(hashes), pruned, nil
+}
+
+func init() {
+	repackCmd.Flags().BoolVarP(&repackAll, "all", "a", false, "Pack everything into a single pack")
+	repackCmd.Flags().BoolVarP(&repackDelete, "delete", "d", false, "Remove redundant packs and loose objects that are packed")
+	repackCmd.Flags().IntVar(&repackWindow, "window", utils.DefaultPackOptions.Window, "Number of objects considered as delta base")
+	repackCmd.Flags().IntVar(&repackDepth, "depth", utils.DefaultPackOptions.MaxDepth, "Maximum delta depth")
+	rootCmd.AddCommand(repackCmd)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var resetSoft bool  // Only move current branch
+var resetMixed bool // Move current branch and reset index (default)
+var resetHard bool  // Move current branch and reset index and working tree
+
+// resetCmd represents the reset command
+var resetCmd = &cobra.Command{
+	Use:   "reset [--soft | --mixed | --hard] [<commit>] [--] [<paths>...]",
+	Short: "Reset current HEAD to the specified state",
+	Run: func(cmd *cobra.Command, args []string) {
+		modeCount := 0
+		for _, isSet := range []bool{resetSoft, resetMixed, resetHard} {
+			if isSet {
+				modeCount++
+			}
+		}
+		if modeCount > 1 {
+			fmt.Println("fatal: --soft, --mixed and --hard cannot be used together")
+			os.Exit(1)
+		}
+
+		// Arguments before "--" are commit, after are paths, without "--" first argument is commit when it resolves
+		revision := "HEAD"
+		var pathArgs []string
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			if dash > 1 {
+				fmt.Println("fatal: only one commit can be given before '--'")
+				os.Exit(1)
+			}
+			if dash == 1 {
+				revision = args[0]
+			}
+			pathArgs = args[dash:]
+		} else if len(args) > 0 {
+			if _, err := utils.ResolveRevision(args[0]); err == nil {
+				revision, pathArgs = args[0], args[1:]
+			} else if _, statErr := os.Lstat(args[0]); statErr == nil {
+				pathArgs = args
+			} else {
+				fmt.Printf("fatal: ambiguous argument '%s': %v\n", args[0], err)
+				os.Exit(1)
+			}
+		}
+
+		// Paths can be unstaged before first commit, everything in index is new then
+		if revision == "HEAD" && utils.GetCurrentCommit() == "" && len(pathArgs) > 0 {
+			resetPaths("", pathArgs)
+			return
+		}
+
+		targetCommit, err := utils.ResolveRevision(revision)
+		if err != nil {
+			fmt.Printf("fatal: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(pathArgs) > 0 {
+			if resetSoft || resetHard {
+				fmt.Println("fatal: Cannot do soft or hard reset with paths.")
+				os.Exit(1)
+			}
+			resetPaths(targetCommit, pathArgs)
+			return
+		}
+
+		switch {
+		case resetSoft:
+			if _, isMerging := utils.ReadStateFile("MERGE_HEAD"); isMerging {
+				fmt.Println("fatal: Cannot do a soft reset in the middle of a merge.")
+				os.Exit(1)
+			}
+			utils.UpdateCommitHashValue(targetCommit, "reset: moving to "+revision)
+		case resetHard:
+			err = utils.CheckoutCommit(targetCommit, true)
+			if err != nil {
+				log.Fatalln("Error while resetting working tree:", err)
+			}
+			utils.UpdateCommitHashValue(targetCommit, "reset: moving to "+revision)
+
+			commit, err := utils.ReadCommit(targetCommit)
+			if err != nil {
+				log.Fatalln("Error when reading commit object:", err)
+			}
+			fmt.Printf("HEAD is now at %s %s\n", targetCommit[:7], commit.Subject())
+		default:
+			entries, err := utils.ReadIndexFile()
+			if err != nil {
+				log.Fatalln("Error while reading index file")
+			}
+			err = utils.WriteIndexFile(resetEntries(entries, utils.GetCommitFiles(targetCommit), nil))
+			if err != nil {
+				log.Fatalln("Error while writing index file:", err)
+			}
+			utils.UpdateCommitHashValue(targetCommit, "reset: moving to "+revision)
+			printUnstagedChanges()
+		}
+
+		// Reset ends merge (or stopped cherry-pick and revert) in progress
+		if !resetSoft {
+			utils.RemoveStateFiles("MERGE_HEAD", "CHERRY_PICK_HEAD", "REVERT_HEAD", "MERGE_MSG")
+		}
+	},
+}
+
+// Set index entries of paths matching pathspecs to their version in target commit, other entries are kept
+func resetPaths(targetCommit string, pathArgs []string) {
+	var pathspecs []string
+	for _, arg := range pathArgs {
+		pathspec, err := utils.ToRepoPath(arg)
+		if err != nil {
+			log.Fatalf("fatal: %v", err)
+		}
+		pathspecs = append(pathspecs, pathspec)
+	}
+
+	entries, err := utils.ReadIndexFile()
+	if err != nil {
+		log.Fatalln("Error while reading index file")
+	}
+
+	err = utils.WriteIndexFile(resetEntries(entries, utils.GetCommitFiles(targetCommit), pathspecs))
+	if err != nil {
+		log.Fatalln("Error while writing index file:", err)
+	}
+	printUnstagedChanges()
+}
+
+// Build index entries from target entries for paths matching pathspecs (every path when pathspecs is nil)
+// Cached stat data is kept for entries whose content doesn't change, so unchanged files are not hashed again
+func resetEntries(entries []utils.IndexEntry, targetEntries []utils.IndexEntry, pathspecs []string) []utils.IndexEntry {
+	matches := func(path string) bool {
+		if pathspecs == nil {
+			return true
+		}
+		for _, pathspec := range pathspecs {
+			if utils.MatchPathspec(pathspec, path) {
+				return true
+			}
+		}
+		return false
+	}
+
+	currentFiles := utils.EntriesToMap(entries)
+	var newEntries []utils.IndexEntry
+	for _, entry := range entries {
+		if !matches(entry.Path) {
+			newEntries = append(newEntries, entry)
+		}
+	}
+	for _, targetEntry := range targetEntries {
+		if !matches(targetEntry.Path) {
+			continue
+		}
+		if current, ok := currentFiles[targetEntry.Path]; ok && current.Stage() == 0 && current.SameContent(targetEntry) {
+			targetEntry = current
+		}
+		newEntries = append(newEntries, targetEntry)
+	}
+
+	return newEntries
+}
+
+// Print tracked files whose working tree content differs from index after reset
+func printUnstagedChanges() {
+	entries, err := utils.ReadIndexFile()
+	if err != nil {
+		log.Fatalln("Error while reading index file")
+	}
+
+	printedHeader := false
+	for _, entry := range entries {
+		status := ""
+		if fileInfo, err := os.Lstat(entry.Path); err != nil {
+			status = "D"
+		} else if isModified, err := utils.IsEntryModified(entry, fileInfo); err == nil && isModified {
+			status = "M"
+		}
+		if status == "" {
+			continue
+		}
+
+		if !printedHeader {
+			fmt.Println("Unstaged changes after reset:")
+			printedHeader = true
+		}
+		fmt.Printf("%s\t%s\n", status, entry.Path)
+	}
+}
+
+func init() {
+	resetCmd.Flags().BoolVar(&resetSoft, "soft", false, "Reset only HEAD")
+	resetCmd.Flags().BoolVar(&resetMixed, "mixed", false, "Reset HEAD and index")
+	resetCmd.Flags().BoolVar(&resetHard, "hard", false, "Reset HEAD, index and working tree")
+	rootCmd.AddCommand(resetCmd)
+}
@@ -0,0 +1,25 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// revertCmd represents the revert command
+var revertCmd = &cobra.Command{
+	Use:   "revert <commit>... | --continue | --abort | --skip",
+	Short: "Revert some existing commits",
+	Run: func(cmd *cobra.Command, args []string) {
+		runSequencerCommand("revert", args)
+	},
+}
+
+func init() {
+	// Flags share variables with cherry-pick, only one of both commands runs at a time
+	revertCmd.Flags().BoolVar(&sequencerContinue, "continue", false, "Resume revert after resolving conflicts")
+	revertCmd.Flags().BoolVar(&sequencerAbort, "abort", false, "Cancel revert and return to the pre-sequence state")
+	revertCmd.Flags().BoolVar(&sequencerSkip, "skip", false, "Skip the current commit and continue with the rest")
+	rootCmd.AddCommand(revertCmd)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var rmCached bool    // Only remove from index, working tree file is kept
+var rmRecursive bool // Allow removing every file inside folder
+var rmForce bool     // Remove even when file has changes that would be lost
+
+// rmCmd represents the rm command
+var rmCmd = &cobra.Command{
+	Use:   "rm [-f] [--cached] [-r] <pathspec>...",
+	Short: "Remove files from the working tree and from the index",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := utils.ReadIndexFile()
+		if err != nil {
+			log.Fatalln("Error while reading index file")
+		}
+
+		// Collect every index entry matching pathspecs, folder needs -r
+		toRemove := make(map[string]bool)
+		var paths []string
+		for _, arg := range args {
+			pathspec, err := utils.ToRepoPath(arg)
+			if err != nil {
+				log.Fatalf("fatal: %v", err)
+			}
+
+			isMatched := false
+			for _, entry := range entries {
+				if !utils.MatchPathspec(pathspec, entry.Path) {
+					continue
+				}
+				if entry.Path != pathspec && !utils.IsGlobPathspec(pathspec) && !rmRecursive {
+					fmt.Printf("fatal: not removing '%s' recursively without -r\n", pathspec)
+					os.Exit(1)
+				}
+				isMatched = true
+				if !toRemove[entry.Path] {
+					toRemove[entry.Path] = true
+					paths = append(paths, entry.Path)
+				}
+			}
+			if !isMatched {
+				fmt.Printf("fatal: pathspec '%s' did not match any files\n", arg)
+				os.Exit(1)
+			}
+		}
+
+		// Check nothing would be lost before anything is changed
+		if !rmForce {
+			checkRemovable(paths, entries)
+		}
+
+		var remaining []utils.IndexEntry
+		for _, entry := range entries {
+			if !toRemove[entry.Path] {
+				remaining = append(remaining, entry)
+			}
+		}
+		err = utils.WriteIndexFile(remaining)
+		if err != nil {
+			log.Fatalln("Error while writing index file:", err)
+		}
+
+		for _, path := range paths {
+			fmt.Printf("rm '%s'\n", path)
+			if rmCached {
+				continue
+			}
+			err := utils.RemoveWorkingFile(path)
+			if err != nil {
+				log.Fatalf("fatal: cannot remove '%s': %v", path, err)
+			}
+		}
+	},
+}
+
+// Exit with error when removing would lose content that is only in index or working tree (same checks as Git)
+func checkRemovable(paths []string, entries []utils.IndexEntry) {
+	headFiles := utils.EntriesToMap(utils.GetCommitFiles(utils.GetCurrentCommit()))
+	indexFiles := utils.EntriesToMap(entries)
+
+	var stagedAndModified, staged, modified []string
+	for _, path := range paths {
+		entry := indexFiles[path]
+		if entry.Stage() != 0 {
+			continue // Conflicted file is always removable
+		}
+
+		headEntry, inHead := headFiles[path]
+		isStaged := !inHead || !headEntry.SameContent(entry)
+
+		isModified := false
+		if fileInfo, err := os.Lstat(path); err == nil {
+			isModified, err = utils.IsEntryModified(entry, fileInfo)
+			if err != nil {
+				log.Fatalf("Cannot read the content of file '%s'", path)
+			}
+		}
+
+		switch {
+		case isStaged && isModified:
+			stagedAndModified = append(stagedAndModified, path)
+		case isStaged && !rmCached:
+			staged = append(staged, path)
+		case isModified && !rmCached:
+			modified = append(modified, path)
+		}
+	}
+
+	failed := false
+	printProblem := func(problem string, files []string, hint string) {
+		if len(files) == 0 {
+			return
+		}
+		failed = true
+		noun := "file has"
+		if len(files) > 1 {
+			noun = "files have"
+		}
+		fmt.Printf("error: the following %s %s:\n", noun, problem)
+		for _, file := range files {
+			fmt.Printf("    %s\n", file)
+		}
+		fmt.Println(hint)
+	}
+
+	printProblem("staged content different from both the\nfile and the HEAD", stagedAndModified, "(use -f to force removal)")
+	printProblem("changes staged in the index", staged, "(use --cached to keep the file, or -f to force removal)")
+	printProblem("local modifications", modified, "(use --cached to keep the file, or -f to force removal)")
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rmCmd.Flags().BoolVar(&rmCached, "cached", false, "Only remove from the index")
+	rmCmd.Flags().BoolVarP(&rmRecursive, "recursive", "r", false, "Allow recursive removal")
+	rmCmd.Flags().BoolVarP(&rmForce, "force", "f", false, "Override the up-to-date check")
+	rootCmd.AddCommand(rmCmd)
+}
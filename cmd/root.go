@@ -5,22 +5,75 @@ package cmd
 
 import (
 	"fmt"
+	"log"
 	"os"
 
+	"github.com/Kei-K23/git-go/internal/utils"
 	"github.com/spf13/cobra"
 )
 
+var changeDir string    // variable to store folder to run command in (-C)
+var gitDirPath string   // variable to store path of repository folder (--git-dir)
+var workTreePath string // variable to store path of working tree (--work-tree)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "git-go",
 	Short: "A Git implementation in Go",
 	Long:  `git-go is a lightweight version of Git implemented in Go.`,
 
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Run command as if git-go was started in the given folder
+		if changeDir != "" {
+			err := os.Chdir(changeDir)
+			if err != nil {
+				log.Fatalf("fatal: cannot change to '%s': %v", changeDir, err)
+			}
+		}
+
+		if !needsRepository(cmd) {
+			// Commands like init create repository in the given folder instead of discovering it
+			if gitDirPath != "" {
+				utils.SetGitDir(gitDirPath)
+			} else if envGitDir := os.Getenv("GIT_GO_DIR"); envGitDir != "" {
+				utils.SetGitDir(envGitDir)
+			}
+			return
+		}
+
+		setupRepository()
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Welcome to git-go! Use 'git-go --help' for usage information.")
 	},
 }
 
+// Discover repository from global flags and environment, then move into working tree root
+func setupRepository() {
+	err := utils.SetupRepository(gitDirPath, workTreePath)
+	if err != nil {
+		fmt.Printf("fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// Check command works inside existing repository (help, completion, init and clone don't, hash-object and config only need it in some cases)
+func needsRepository(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case "init", "clone", "upload-pack", "receive-pack", "help", "completion", "hash-object", "config", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return false
+		}
+	}
+	return cmd.HasParent()
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&changeDir, "chdir", "C", "", "Run as if git-go was started in the given path")
+	rootCmd.PersistentFlags().StringVar(&gitDirPath, "git-dir", "", "Set the path to the repository (.git-go folder)")
+	rootCmd.PersistentFlags().StringVar(&workTreePath, "work-tree", "", "Set the path to the working tree")
+}
+
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
@@ -0,0 +1,509 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Kei-K23/git-go/internal/diff"
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var stashUntracked bool // Also stash untracked files (-u)
+var stashMessage string // Description of stash entry
+var stashIndex bool     // Restore staged changes to index too when applying
+var stashPatch bool     // Show stash entry as patch instead of diffstat
+
+// stashCmd represents the stash command
+var stashCmd = &cobra.Command{
+	Use:   "stash [push [-u] [-m <message>] | list | show [-p] [<stash>] | apply [--index] [<stash>] | pop [--index] [<stash>] | drop [<stash>] | clear]",
+	Short: "Stash the changes in a dirty working directory away",
+	Run: func(cmd *cobra.Command, args []string) {
+		subcommand := "push"
+		if len(args) > 0 {
+			subcommand, args = args[0], args[1:]
+		}
+
+		stashName := ""
+		if subcommand != "push" && len(args) > 0 {
+			if len(args) > 1 {
+				fmt.Println("fatal: too many arguments")
+				os.Exit(1)
+			}
+			stashName = args[0]
+		}
+
+		switch subcommand {
+		case "push":
+			if len(args) > 0 {
+				fmt.Println("fatal: pathspecs are not supported by stash push")
+				os.Exit(1)
+			}
+			pushStash()
+		case "list":
+			stashes, err := utils.ListStashes()
+			if err != nil {
+				log.Fatalln("Error while reading stash entries:", err)
+			}
+			for i, stash := range stashes {
+				fmt.Printf("stash@{%d}: %s\n", i, stash.Message)
+			}
+		case "show":
+			showStash(stashName)
+		case "apply":
+			if applyStash(stashName, stashIndex) {
+				os.Exit(1)
+			}
+		case "pop":
+			number := stashNumber(stashName)
+			if applyStash(stashName, stashIndex) {
+				fmt.Println("The stash entry is kept in case you need it again.")
+				os.Exit(1)
+			}
+			dropStash(number)
+		case "drop":
+			dropStash(stashNumber(stashName))
+		case "clear":
+			if utils.ReadRef(utils.StashRef) == "" {
+				return
+			}
+			err := utils.DeleteRef(utils.StashRef)
+			if err != nil {
+				log.Fatalln("Error while removing stash entries:", err)
+			}
+		default:
+			fmt.Printf("error: unknown subcommand: `%s'\n", subcommand)
+			os.Exit(1)
+		}
+	},
+}
+
+// Record index and working tree (and untracked files with -u) as stash commits, then reset them to HEAD
+// Stash commit has HEAD, index commit and untracked files commit as parents (same layout as Git)
+func pushStash() {
+	entries, err := utils.ReadIndexFile()
+	if err != nil {
+		log.Fatalln("Error while reading index file")
+	}
+	if unmergedPaths := utils.UnmergedPaths(entries); len(unmergedPaths) > 0 {
+		for _, path := range unmergedPaths {
+			fmt.Printf("%s: needs merge\n", path)
+		}
+		fmt.Println("error: could not save the current state of the index")
+		os.Exit(1)
+	}
+
+	headCommit := utils.GetCurrentCommit()
+	if headCommit == "" {
+		fmt.Println("You do not have the initial commit yet")
+		os.Exit(1)
+	}
+
+	localChanges, err := utils.LocalChanges()
+	if err != nil {
+		log.Fatalln("Error while reading index file:", err)
+	}
+
+	var untrackedFiles []string
+	if stashUntracked {
+		workingFiles, err := utils.ListWorkingFiles()
+		if err != nil {
+			log.Fatalln("Error while reading working directory:", err)
+		}
+		indexFiles := utils.EntriesToMap(entries)
+		for _, file := range workingFiles {
+			if _, ok := indexFiles[file]; !ok {
+				untrackedFiles = append(untrackedFiles, file)
+			}
+		}
+	}
+
+	if len(localChanges) == 0 && len(untrackedFiles) == 0 {
+		fmt.Println("No local changes to save")
+		return
+	}
+
+	signature, err := utils.GetUserSignature()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	headSubject := ""
+	if commit, err := utils.ReadCommit(headCommit); err == nil {
+		headSubject = commit.Subject()
+	}
+	branch := utils.GerCurrentBranch()
+	if branch == "" {
+		branch = "(no branch)"
+	}
+	onBranch := fmt.Sprintf("%s: %s %s", branch, headCommit[:7], headSubject)
+
+	writeStashCommit := func(snapshot []utils.IndexEntry, parents []string, message string) string {
+		treeHash, err := utils.WriteTree(snapshot)
+		if err != nil {
+			log.Fatalln("Error when creating tree object:", err)
+		}
+		commitHash, err := utils.WriteCommit(utils.Commit{
+			Tree:      treeHash,
+			Parents:   parents,
+			Author:    signature,
+			Committer: signature,
+			Message:   message,
+		})
+		if err != nil {
+			log.Fatalln("Error when creating commit object:", err)
+		}
+		return commitHash
+	}
+
+	indexCommit := writeStashCommit(entries, []string{headCommit}, "index on "+onBranch)
+	parents := []string{headCommit, indexCommit}
+
+	if len(untrackedFiles) > 0 {
+		var untrackedEntries []utils.IndexEntry
+		for _, file := range untrackedFiles {
+			entry, err := utils.StoreWorkingFile(file)
+			if err != nil {
+				log.Fatalf("Cannot read the content of file '%s'", file)
+			}
+			untrackedEntries = append(untrackedEntries, entry)
+		}
+		parents = append(parents, writeStashCommit(untrackedEntries, nil, "untracked files on "+onBranch))
+	}
+
+	workingEntries, err := utils.WorkingTreeEntries(entries)
+	if err != nil {
+		log.Fatalln("Error while reading working tree files:", err)
+	}
+
+	message := "WIP on " + onBranch
+	if stashMessage != "" {
+		message = fmt.Sprintf("On %s: %s", branch, stashMessage)
+	}
+	stashCommit := writeStashCommit(workingEntries, parents, message)
+
+	err = utils.UpdateRef(utils.StashRef, stashCommit, message)
+	if err != nil {
+		log.Fatalln("Error while updating stash reference:", err)
+	}
+
+	// Local changes are saved, so working tree and index go back to HEAD
+	err = utils.CheckoutCommit(headCommit, true)
+	if err != nil {
+		log.Fatalln("Error while resetting working tree:", err)
+	}
+	for _, file := range untrackedFiles {
+		err = utils.RemoveWorkingFile(file)
+		if err != nil {
+			log.Fatalf("Error while removing untracked file '%s': %v", file, err)
+		}
+	}
+
+	fmt.Printf("Saved working directory and index state %s\n", message)
+}
+
+// Resolve stash name and read its commit, exit when it is not made by stash push
+func readStashCommit(name string) (string, utils.Commit) {
+	stashHash, err := utils.ResolveStash(name)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+
+	commit, err := utils.ReadCommit(stashHash)
+	if err != nil || len(commit.Parents) < 2 {
+		fmt.Printf("error: '%s' is not a stash-like commit\n", name)
+		os.Exit(1)
+	}
+	return stashHash, commit
+}
+
+// Get entry number of stash name used by drop and pop ("" is the latest stash)
+func stashNumber(name string) int {
+	if name == "" {
+		return 0
+	}
+	if number, err := strconv.Atoi(name); err == nil && number >= 0 {
+		return number
+	}
+	if ref, number, ok := utils.ParseReflogSelector(name); ok && (ref == "stash" || ref == utils.StashRef) {
+		return number
+	}
+
+	fmt.Printf("error: '%s' is not a stash reference\n", name)
+	os.Exit(1)
+	return 0
+}
+
+// Remove stash entry and print what was dropped
+func dropStash(number int) {
+	dropped, err := utils.DropStash(number)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Dropped refs/stash@{%d} (%s)\n", number, dropped.NewHash)
+}
+
+// Print changes recorded in stash entry compared to commit it was created on
+func showStash(name string) {
+	stashHash, commit := readStashCommit(name)
+	baseEntries := utils.GetCommitFiles(commit.Parents[0])
+	stashEntries := utils.GetCommitFiles(stashHash)
+
+	if stashPatch {
+		printTreeDiff(baseEntries, stashEntries, false)
+		return
+	}
+	printDiffStat(baseEntries, stashEntries)
+}
+
+// Print number of changed lines per file with "+" and "-" graph, followed by summary line
+func printDiffStat(oldEntries, newEntries []utils.IndexEntry) {
+	oldFiles := utils.EntriesToMap(oldEntries)
+	newFiles := utils.EntriesToMap(newEntries)
+
+	pathSet := make(map[string]bool)
+	for _, files := range []map[string]utils.IndexEntry{oldFiles, newFiles} {
+		for path := range files {
+			pathSet[path] = true
+		}
+	}
+	var paths []string
+	for path := range pathSet {
+		oldEntry, inOld := oldFiles[path]
+		newEntry, inNew := newFiles[path]
+		if !inOld || !inNew || !oldEntry.SameContent(newEntry) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	type fileStat struct {
+		path        string
+		insertions  int
+		deletions   int
+		binaryStats string
+	}
+	var stats []fileStat
+	nameWidth, maxChanges, totalInsertions, totalDeletions := 0, 0, 0, 0
+	for _, path := range paths {
+		var oldContent, newContent []byte
+		if entry, ok := oldFiles[path]; ok {
+			oldContent = readDiffBlob(entry.Hash)
+		}
+		if entry, ok := newFiles[path]; ok {
+			newContent = readDiffBlob(entry.Hash)
+		}
+
+		stat := fileStat{path: path}
+		if diff.IsBinary(oldContent) || diff.IsBinary(newContent) {
+			stat.binaryStats = fmt.Sprintf("Bin %d -> %d bytes", len(oldContent), len(newContent))
+		} else {
+			for _, edit := range diff.Lines(oldContent, newContent) {
+				switch edit.Op {
+				case diff.Insert:
+					stat.insertions++
+				case diff.Delete:
+					stat.deletions++
+				}
+			}
+		}
+
+		stats = append(stats, stat)
+		nameWidth = max(nameWidth, len(path))
+		maxChanges = max(maxChanges, stat.insertions+stat.deletions)
+		totalInsertions += stat.insertions
+		totalDeletions += stat.deletions
+	}
+
+	// Graph is scaled down when the biggest change doesn't fit
+	const graphWidth = 50
+	countWidth := len(strconv.Itoa(maxChanges))
+	for _, stat := range stats {
+		if stat.binaryStats != "" {
+			fmt.Printf(" %-*s | %s\n", nameWidth, stat.path, stat.binaryStats)
+			continue
+		}
+		insertions, deletions := stat.insertions, stat.deletions
+		if maxChanges > graphWidth {
+			insertions = (insertions*graphWidth + maxChanges - 1) / maxChanges
+			deletions = (deletions*graphWidth + maxChanges - 1) / maxChanges
+		}
+		fmt.Printf(" %-*s | %*d %s%s\n", nameWidth, stat.path, countWidth, stat.insertions+stat.deletions,
+			strings.Repeat("+", insertions), strings.Repeat("-", deletions))
+	}
+
+	summary := fmt.Sprintf(" %d file%s changed", len(stats), plural(len(stats)))
+	if totalInsertions > 0 || totalDeletions == 0 {
+		summary += fmt.Sprintf(", %d insertion%s(+)", totalInsertions, plural(totalInsertions))
+	}
+	if totalDeletions > 0 || totalInsertions == 0 {
+		summary += fmt.Sprintf(", %d deletion%s(-)", totalDeletions, plural(totalDeletions))
+	}
+	fmt.Println(summary)
+}
+
+// Get "s" suffix for counts other than one
+func plural(count int) string {
+	if count == 1 {
+		return ""
+	}
+	return "s"
+}
+
+// Merge stash changes into working tree (and index with restoreIndex), true is returned when there are conflicts
+// Without restoreIndex staged changes become unstaged, only files added by stash stay in index (same as Git)
+func applyStash(name string, restoreIndex bool) bool {
+	stashHash, commit := readStashCommit(name)
+
+	entries, err := utils.ReadIndexFile()
+	if err != nil {
+		log.Fatalln("Error while reading index file")
+	}
+	if len(utils.UnmergedPaths(entries)) > 0 {
+		fmt.Println("error: Cannot apply a stash in the middle of a merge")
+		os.Exit(1)
+	}
+
+	baseEntries := utils.GetCommitFiles(commit.Parents[0])
+	stashEntries := utils.GetCommitFiles(stashHash)
+
+	// Untracked files are only restored where nothing exists yet
+	var untrackedEntries []utils.IndexEntry
+	if len(commit.Parents) > 2 {
+		untrackedEntries = utils.GetCommitFiles(commit.Parents[2])
+		isBlocked := false
+		for _, entry := range untrackedEntries {
+			if _, err := os.Lstat(entry.Path); err == nil {
+				fmt.Printf("%s already exists, no checkout\n", entry.Path)
+				isBlocked = true
+			}
+		}
+		if isBlocked {
+			fmt.Println("error: could not restore untracked files from stash")
+			os.Exit(1)
+		}
+	}
+
+	// Staged changes are merged into current index first, so nothing is touched when they conflict
+	var indexResult utils.MergeResult
+	if restoreIndex {
+		indexResult, err = utils.MergeTrees(baseEntries, entries, utils.GetCommitFiles(commit.Parents[1]), "Updated upstream", "Stashed changes")
+		if err != nil {
+			log.Fatalln("Error while merging:", err)
+		}
+		if len(indexResult.Conflicts) > 0 {
+			fmt.Println("error: conflicts in index. Try without --index.")
+			os.Exit(1)
+		}
+	}
+
+	// Files changed by stash must not have unstaged changes that would be overwritten
+	baseFiles := utils.EntriesToMap(baseEntries)
+	stashFiles := utils.EntriesToMap(stashEntries)
+	var localChanges []string
+	for _, entry := range entries {
+		baseEntry, inBase := baseFiles[entry.Path]
+		stashEntry, inStash := stashFiles[entry.Path]
+		if inBase == inStash && baseEntry.SameContent(stashEntry) {
+			continue
+		}
+		fileInfo, err := os.Lstat(entry.Path)
+		if err != nil {
+			localChanges = append(localChanges, entry.Path)
+			continue
+		}
+		if isModified, err := utils.IsEntryModified(entry, fileInfo); err != nil || isModified {
+			localChanges = append(localChanges, entry.Path)
+		}
+	}
+	if len(localChanges) > 0 {
+		fmt.Println("error: Your local changes to the following files would be overwritten by merge:")
+		for _, path := range localChanges {
+			fmt.Printf("\t%s\n", path)
+		}
+		fmt.Println("Please commit your changes or stash them before you merge.")
+		fmt.Println("Aborting")
+		os.Exit(1)
+	}
+
+	result, err := utils.MergeTrees(baseEntries, entries, stashEntries, "Updated upstream", "Stashed changes")
+	if err != nil {
+		log.Fatalln("Error while merging:", err)
+	}
+	err = utils.ApplyMergeResult(result)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, entry := range untrackedEntries {
+		err = utils.WriteWorkingFile(entry)
+		if err != nil {
+			log.Fatalf("Error while restoring untracked file '%s': %v", entry.Path, err)
+		}
+	}
+
+	if len(result.Conflicts) > 0 {
+		for _, path := range result.Conflicts {
+			fmt.Printf("CONFLICT (content): Merge conflict in %s\n", path)
+		}
+		return true
+	}
+
+	// Choose index content, entries whose content is already in working tree keep stat data from applying
+	var targetEntries []utils.IndexEntry
+	if restoreIndex {
+		targetEntries = indexResult.Entries
+	} else {
+		targetEntries = entries
+		indexFiles := utils.EntriesToMap(entries)
+		for _, entry := range result.Entries {
+			if _, ok := indexFiles[entry.Path]; !ok {
+				targetEntries = append(targetEntries, entry)
+			}
+		}
+	}
+
+	appliedEntries, err := utils.ReadIndexFile()
+	if err != nil {
+		log.Fatalln("Error while reading index file")
+	}
+	appliedFiles := utils.EntriesToMap(appliedEntries)
+
+	var newEntries []utils.IndexEntry
+	for _, entry := range targetEntries {
+		if applied, ok := appliedFiles[entry.Path]; ok && applied.SameContent(entry) {
+			newEntries = append(newEntries, applied)
+		} else {
+			// Stat data is left empty, so file content is compared next time
+			newEntries = append(newEntries, utils.IndexEntry{Mode: entry.Mode, Hash: entry.Hash, Path: entry.Path})
+		}
+	}
+	utils.SortEntries(newEntries)
+
+	err = utils.WriteIndexFile(newEntries)
+	if err != nil {
+		log.Fatalln("Error while writing index file:", err)
+	}
+
+	statusCmd.Run(statusCmd, nil)
+	return false
+}
+
+func init() {
+	stashCmd.Flags().BoolVarP(&stashUntracked, "include-untracked", "u", false, "Include untracked files in stash")
+	stashCmd.Flags().StringVarP(&stashMessage, "message", "m", "", "Description of the stash entry")
+	stashCmd.Flags().BoolVar(&stashIndex, "index", false, "Try to reinstate index changes as well")
+	stashCmd.Flags().BoolVarP(&stashPatch, "patch", "p", false, "Show the changes as patch")
+	rootCmd.AddCommand(stashCmd)
+}
@@ -0,0 +1,207 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the working tree status",
+	Run: func(cmd *cobra.Command, args []string) {
+		if currentBranch := utils.GerCurrentBranch(); currentBranch != "" {
+			fmt.Printf("On branch %s\n", currentBranch)
+		} else {
+			fmt.Printf("HEAD detached at %s\n", utils.GetCurrentCommit()[:7])
+		}
+
+		// Get snapshot of HEAD commit to compare with staging area
+		headCommit := utils.GetCurrentCommit()
+		if headCommit == "" {
+			fmt.Println("\nNo commits yet")
+		}
+
+		if headName, isRebasing := readRebaseState("head-name"); isRebasing {
+			onto, _ := readRebaseState("onto")
+			fmt.Printf("\nYou are currently rebasing '%s' on '%s'.\n", strings.TrimPrefix(headName, "refs/heads/"), onto[:min(7, len(onto))])
+			fmt.Println("  (fix conflicts and then run \"git-go rebase --continue\")")
+			fmt.Println("  (use \"git-go rebase --skip\" to skip this patch)")
+			fmt.Println("  (use \"git-go rebase --abort\" to check out the original branch)")
+		}
+
+		for _, action := range []string{"cherry-pick", "revert"} {
+			if stoppedHash, isStopped := utils.ReadStateFile(sequencerHeadFile(action)); isStopped {
+				fmt.Printf("\nYou are currently %sing commit %s.\n", strings.TrimSuffix(action, "e"), strings.TrimSpace(stoppedHash)[:7])
+				fmt.Printf("  (fix conflicts and run \"git-go %s --continue\")\n", action)
+				fmt.Printf("  (use \"git-go %s --skip\" to skip this patch)\n", action)
+				fmt.Printf("  (use \"git-go %s --abort\" to cancel the %s operation)\n", action, action)
+			}
+		}
+
+		if _, isMerging := utils.ReadStateFile("MERGE_HEAD"); isMerging {
+			fmt.Println("\nYou have unmerged paths.")
+			fmt.Println("  (fix conflicts and run \"git-go commit\")")
+			fmt.Println("  (use \"git-go merge --abort\" to abort the merge)")
+		}
+
+		headEntries := utils.GetCommitFiles(headCommit)
+		headFiles := make(map[string]string)
+		for _, entry := range headEntries {
+			headFiles[entry.Path] = entry.Hash
+		}
+
+		entries, err := utils.ReadIndexFile()
+		if err != nil {
+			log.Fatalln("Error while reading index file")
+		}
+
+		indexFiles := make(map[string]string)
+		for _, entry := range entries {
+			indexFiles[entry.Path] = entry.Hash
+		}
+
+		workingFiles, err := utils.ListWorkingFiles()
+		if err != nil {
+			log.Fatalln("Error while reading working directory:", err)
+		}
+
+		// Paths with conflicts, described by which merge stages exist
+		var unmerged []string
+		stages := make(map[string][4]bool)
+		for _, entry := range entries {
+			if entry.Stage() != 0 {
+				pathStages := stages[entry.Path]
+				pathStages[entry.Stage()] = true
+				stages[entry.Path] = pathStages
+			}
+		}
+		for _, path := range utils.UnmergedPaths(entries) {
+			unmerged = append(unmerged, describeUnmerged(path, stages[path]))
+		}
+
+		// Changes between HEAD and staging area
+		var staged []string
+		for _, entry := range entries {
+			if entry.Stage() != 0 {
+				continue
+			}
+			headHash, ok := headFiles[entry.Path]
+			if !ok {
+				staged = append(staged, fmt.Sprintf("new file:   %s", entry.Path))
+			} else if headHash != entry.Hash {
+				staged = append(staged, fmt.Sprintf("modified:   %s", entry.Path))
+			}
+		}
+		for _, entry := range headEntries {
+			if _, ok := indexFiles[entry.Path]; !ok {
+				staged = append(staged, fmt.Sprintf("deleted:    %s", entry.Path))
+			}
+		}
+
+		// Changes between staging area and working directory
+		var unstaged []string
+		isIndexRefreshed := false
+		for i, entry := range entries {
+			if entry.Stage() != 0 {
+				continue
+			}
+			fileInfo, err := os.Lstat(entry.Path)
+			if err != nil {
+				unstaged = append(unstaged, fmt.Sprintf("deleted:    %s", entry.Path))
+				continue
+			}
+
+			// Cached stat data is same, so file content doesn't need to be hashed
+			if utils.IsEntryStatClean(entry, fileInfo) {
+				continue
+			}
+
+			workingHash, err := utils.HashWorkingFile(entry.Path)
+			if err != nil {
+				log.Fatalf("Cannot read the content of file '%s'", entry.Path)
+			}
+
+			if workingHash != entry.Hash || utils.FileModeOf(fileInfo) != entry.Mode {
+				unstaged = append(unstaged, fmt.Sprintf("modified:   %s", entry.Path))
+			} else {
+				// Only stat data is changed (e.g file is touched), so cache new stat data
+				utils.SetEntryStat(&entries[i], fileInfo)
+				isIndexRefreshed = true
+			}
+		}
+
+		if isIndexRefreshed {
+			// Failing to refresh the cache only makes next status slower
+			utils.WriteIndexFile(entries)
+		}
+
+		// Files that are neither staged nor committed
+		var untracked []string
+		for _, file := range workingFiles {
+			if _, ok := indexFiles[file]; !ok {
+				untracked = append(untracked, file)
+			}
+		}
+
+		printStatusSection("Changes to be committed:", staged)
+		printStatusSection("Unmerged paths:", unmerged)
+		printStatusSection("Changes not staged for commit:", unstaged)
+		printStatusSection("Untracked files:", untracked)
+
+		if len(unmerged) > 0 {
+			return
+		}
+
+		if len(staged) == 0 && len(unstaged) == 0 && len(untracked) == 0 {
+			fmt.Println("\nnothing to commit, working tree clean")
+		} else if len(staged) == 0 && len(unstaged) == 0 {
+			fmt.Println("\nnothing added to commit but untracked files present (use \"git-go add\" to track)")
+		} else if len(staged) == 0 {
+			fmt.Println("\nno changes added to commit (use \"git-go add\")")
+		}
+	},
+}
+
+// Print one section of status output, nothing is printed for empty section
+func printStatusSection(title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s\n", title)
+	for _, line := range lines {
+		fmt.Printf("\t%s\n", line)
+	}
+}
+
+// Describe conflicted path by merge stages it has (1 base, 2 ours, 3 theirs)
+func describeUnmerged(path string, stages [4]bool) string {
+	switch {
+	case stages[1] && stages[2] && stages[3]:
+		return fmt.Sprintf("both modified:   %s", path)
+	case stages[2] && stages[3]:
+		return fmt.Sprintf("both added:      %s", path)
+	case stages[1] && stages[2]:
+		return fmt.Sprintf("deleted by them: %s", path)
+	case stages[1] && stages[3]:
+		return fmt.Sprintf("deleted by us:   %s", path)
+	case stages[2]:
+		return fmt.Sprintf("added by us:     %s", path)
+	case stages[3]:
+		return fmt.Sprintf("added by them:   %s", path)
+	}
+	return fmt.Sprintf("both deleted:    %s", path)
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var switchCreate bool // variable to store create flag to create new branch before switching
+var switchForce bool  // variable to store force flag to discard local changes
+
+// switchCmd represents the switch command
+var switchCmd = &cobra.Command{
+	Use:   "switch [-c] <branch>",
+	Short: "Switch branches",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		branch := args[0]
+
+		if switchCreate {
+			if err := utils.ValidateRefName("refs/heads/" + branch); err != nil {
+				fmt.Printf("fatal: '%s' is not a valid branch name\n", branch)
+				os.Exit(1)
+			}
+			if utils.BranchExists(branch) {
+				fmt.Printf("fatal: a branch named '%s' already exists\n", branch)
+				os.Exit(1)
+			}
+
+			// New branch start from current commit, there is nothing to checkout
+			currentCommit := utils.GetCurrentCommit()
+			if currentCommit != "" {
+				err := utils.CreateBranch(branch, currentCommit, "branch: Created from HEAD")
+				if err != nil {
+					log.Fatalln("Error while creating new branch:", err)
+				}
+			}
+
+			err := utils.SetHeadToBranch(branch, checkoutMessage(branch))
+			if err != nil {
+				log.Fatalln("Error while updating HEAD file:", err)
+			}
+
+			fmt.Printf("Switched to a new branch '%s'\n", branch)
+			return
+		}
+
+		if !utils.BranchExists(branch) {
+			fmt.Printf("fatal: invalid reference: %s\n", branch)
+			os.Exit(1)
+		}
+
+		switchToBranch(branch, switchForce)
+	},
+}
+
+func init() {
+	switchCmd.Flags().BoolVarP(&switchCreate, "create", "c", false, "Create a new branch and switch to it")
+	switchCmd.Flags().BoolVarP(&switchForce, "force", "f", false, "Throw away local changes")
+	rootCmd.AddCommand(switchCmd)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var tagAnnotate bool  // variable to store -a flag to create annotated tag object
+var tagMessage string // variable to store annotated tag message
+var tagDelete bool    // variable to store -d flag to delete tags
+var tagList bool      // variable to store -l flag to list tags matching patterns
+var tagForce bool     // variable to store -f flag to replace existing tag
+
+// tagCmd represents the tag command
+var tagCmd = &cobra.Command{
+	Use:   "tag [-a] [-m <msg>] [-f] <name> [<commit>] | -d <name>... | [-l] [<pattern>...]",
+	Short: "Create, list or delete tags",
+	Run: func(cmd *cobra.Command, args []string) {
+		if tagDelete {
+			if len(args) == 0 {
+				fmt.Println("fatal: tag name is required")
+				os.Exit(1)
+			}
+
+			exitCode := 0
+			for _, name := range args {
+				// Name must be checked before it is joined onto refs/tags, "../heads/main" would delete a branch
+				if err := utils.ValidateRefName(name); err != nil {
+					fmt.Printf("error: %v\n", err)
+					exitCode = 1
+					continue
+				}
+
+				target := utils.GetTagTarget(name)
+				if target == "" {
+					fmt.Printf("error: tag '%s' not found.\n", name)
+					exitCode = 1
+					continue
+				}
+				err := utils.DeleteTag(name)
+				if err != nil {
+					log.Fatalf("Error while deleting tag '%s': %v", name, err)
+				}
+				fmt.Printf("Deleted tag '%s' (was %s)\n", name, target[:7])
+			}
+			os.Exit(exitCode)
+		}
+
+		// Without tag name (or with -l), show tags matching the glob patterns
+		if tagList || len(args) == 0 {
+			for _, name := range utils.ListRefs("tags") {
+				if matchesAnyPattern(name, args) {
+					fmt.Println(name)
+				}
+			}
+			return
+		}
+
+		if len(args) > 2 {
+			fmt.Println("fatal: too many arguments")
+			os.Exit(1)
+		}
+
+		name := args[0]
+		if err := utils.ValidateRefName(name); err != nil {
+			fmt.Printf("fatal: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Tags are immutable unless -f is given
+		if utils.TagExists(name) && !tagForce {
+			fmt.Printf("fatal: tag '%s' already exists\n", name)
+			os.Exit(1)
+		}
+
+		revision := "HEAD"
+		if len(args) == 2 {
+			revision = args[1]
+		}
+		targetHash, err := utils.ResolveObjectName(revision)
+		if err != nil {
+			fmt.Printf("fatal: Failed to resolve '%s' as a valid ref.\n", revision)
+			os.Exit(1)
+		}
+
+		// Message implies annotated tag (same as Git)
+		if tagAnnotate || tagMessage != "" {
+			if tagMessage == "" {
+				fmt.Println("fatal: no tag message provided. Use -m to provide a message.")
+				os.Exit(1)
+			}
+
+			targetHash, err = writeTagObject(name, targetHash, tagMessage)
+			if err != nil {
+				log.Fatalln("Error when creating tag object:", err)
+			}
+		}
+
+		err = utils.CreateTag(name, targetHash)
+		if err != nil {
+			log.Fatalln("Error while creating tag:", err)
+		}
+	},
+}
+
+// Check name matches at least one glob pattern (every name matches when there is no pattern)
+func matchesAnyPattern(name string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Create annotated tag object with tagger identity and message, then return its hash value
+func writeTagObject(name string, targetHash string, message string) (string, error) {
+	targetType, _, err := utils.ReadObject(targetHash)
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := utils.GetUserSignature()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var tagObjSb strings.Builder
+	tagObjSb.WriteString(fmt.Sprintf("object %s\n", targetHash))
+	tagObjSb.WriteString(fmt.Sprintf("type %s\n", targetType))
+	tagObjSb.WriteString(fmt.Sprintf("tag %s\n", name))
+	tagObjSb.WriteString(fmt.Sprintf("tagger %s\n", signature))
+	tagObjSb.WriteString(fmt.Sprintf("\n%s\n", message))
+
+	return utils.WriteObject(utils.TagObject, []byte(tagObjSb.String()))
+}
+
+func init() {
+	tagCmd.Flags().BoolVarP(&tagAnnotate, "annotate", "a", false, "Create an annotated tag object")
+	tagCmd.Flags().StringVarP(&tagMessage, "message", "m", "", "Tag message")
+	tagCmd.Flags().BoolVarP(&tagDelete, "delete", "d", false, "Delete tags")
+	tagCmd.Flags().BoolVarP(&tagList, "list", "l", false, "List tags matching the patterns")
+	tagCmd.Flags().BoolVarP(&tagForce, "force", "f", false, "Replace an existing tag")
+	rootCmd.AddCommand(tagCmd)
+}
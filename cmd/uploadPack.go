@@ -0,0 +1,153 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Kei-K23/git-go/internal/transport"
+	"github.com/Kei-K23/git-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// uploadPackCmd represents the upload-pack command
+var uploadPackCmd = &cobra.Command{
+	Use:   "upload-pack <directory>",
+	Short: "Send objects to a fetching repository over stdin and stdout",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Stdout is used by protocol, so every message goes to stderr
+		gitDir, err := utils.FindLocalRepository(args[0])
+		if err != nil {
+			log.Fatalf("fatal: %v", err)
+		}
+		utils.SetGitDir(gitDir)
+
+		in := bufio.NewReader(os.Stdin)
+		out := os.Stdout
+
+		refs := advertisedRefs()
+		capabilities := []string{"side-band-64k", "side-band", "ofs-delta", "include-tag"}
+		if branch := utils.GerCurrentBranch(); branch != "" {
+			capabilities = append(capabilities, "symref=HEAD:refs/heads/"+branch)
+		}
+		capabilities = append(capabilities, "agent="+transport.Agent)
+
+		err = transport.WriteAdvertisement(out, refs, capabilities)
+		if err != nil {
+			log.Fatalln("fatal: cannot write ref advertisement:", err)
+		}
+
+		wants, clientCapabilities, err := transport.ReadWants(in)
+		if err != nil {
+			log.Fatalf("fatal: %v", err)
+		}
+		if len(wants) == 0 {
+			return // Client is already up to date
+		}
+		for _, want := range wants {
+			if !utils.HasObject(want) {
+				transport.WritePktString(out, fmt.Sprintf("ERR upload-pack: not our ref %s\n", want))
+				os.Exit(1)
+			}
+		}
+
+		common := negotiateHaves(in, out)
+
+		hashes, err := utils.MissingObjects(wants, common)
+		if err != nil {
+			log.Fatalln("fatal: cannot collect objects:", err)
+		}
+
+		// Annotated tags pointing to sent objects are sent too, so client can follow them
+		if slices.Contains(clientCapabilities, "include-tag") {
+			sending := make(map[string]bool)
+			for _, hashValue := range hashes {
+				sending[hashValue] = true
+			}
+			for _, ref := range refs {
+				if strings.HasPrefix(ref.Name, "refs/tags/") && ref.Peeled != "" && sending[ref.Peeled] && !sending[ref.Hash] {
+					sending[ref.Hash] = true
+					hashes = append(hashes, ref.Hash)
+				}
+			}
+		}
+
+		options := utils.DefaultPackOptions
+		options.RefDelta = !slices.Contains(clientCapabilities, "ofs-delta")
+		packData, _, err := utils.EncodePack(hashes, options)
+		if err != nil {
+			log.Fatalln("fatal: cannot create pack:", err)
+		}
+
+		err = transport.WritePackData(out, packData, clientCapabilities)
+		if err != nil {
+			log.Fatalln("fatal: cannot send pack:", err)
+		}
+	},
+}
+
+// Get refs to advertise, HEAD first and then every ref, annotated tags also tell object they point to
+func advertisedRefs() []transport.Ref {
+	var refs []transport.Ref
+	if head := utils.GetCurrentCommit(); head != "" {
+		refs = append(refs, transport.Ref{Name: "HEAD", Hash: head})
+	}
+
+	for _, name := range utils.ListAllRefs() {
+		ref := transport.Ref{Name: name, Hash: utils.ReadRef(name)}
+		if strings.HasPrefix(name, "refs/tags/") {
+			if peeled, _, err := utils.PeelObject(ref.Hash); err == nil && peeled != ref.Hash {
+				ref.Peeled = peeled
+			}
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
+// Read have lines until done and acknowledge first common commit (no multi_ack), then return common commits
+// NAK is sent at flush or done while no common commit is found yet
+func negotiateHaves(in *bufio.Reader, out *os.File) []string {
+	var common []string
+
+	for {
+		line, err := transport.ReadPktLine(in)
+		if err != nil {
+			log.Fatalf("fatal: %v", err)
+		}
+
+		text := strings.TrimSpace(string(line))
+		if line == nil || text == "done" {
+			if len(common) == 0 {
+				transport.WritePktString(out, "NAK\n")
+			}
+			if text == "done" {
+				return common
+			}
+			continue
+		}
+
+		have, ok := strings.CutPrefix(text, "have ")
+		if !ok {
+			log.Fatalf("fatal: protocol error: expected have line, got '%s'", text)
+		}
+		if utils.HasObject(have) {
+			common = append(common, have)
+			if len(common) == 1 {
+				transport.WritePktString(out, fmt.Sprintf("ACK %s\n", have))
+			}
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(uploadPackCmd)
+}
@@ -0,0 +1,319 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kind of change for a single line
+type Operation int
+
+const (
+	Equal Operation = iota
+	Insert
+	Delete
+)
+
+// Single line change, line numbers start from 0 and are -1 when the line doesn't exist in that side
+type Edit struct {
+	Op      Operation
+	OldLine int
+	NewLine int
+	Text    string
+}
+
+// Split content into lines, each line keep its own "\n" so missing new line at end of file is visible
+func SplitLines(content []byte) []string {
+	var lines []string
+	for len(content) > 0 {
+		index := bytes.IndexByte(content, '\n')
+		if index == -1 {
+			lines = append(lines, string(content))
+			break
+		}
+		lines = append(lines, string(content[:index+1]))
+		content = content[index+1:]
+	}
+	return lines
+}
+
+// Check content looks like binary data (contains NUL byte like Git does)
+func IsBinary(content []byte) bool {
+	limit := len(content)
+	if limit > 8000 {
+		limit = 8000
+	}
+	return bytes.IndexByte(content[:limit], 0) != -1
+}
+
+// Compute the shortest edit script between two line lists with Myers O(ND) algorithm
+// Linear space variant is used, it splits the problem at the middle snake instead of keeping every step to backtrack
+func Myers(a, b []string) []Edit {
+	script := &editScript{a: a, b: b}
+	script.diff(0, len(a), 0, len(b))
+
+	// Show deletions before insertions inside every run of changes (same as Git)
+	edits := script.edits
+	for start := 0; start < len(edits); {
+		if edits[start].Op == Equal {
+			start++
+			continue
+		}
+		end := start
+		for end < len(edits) && edits[end].Op != Equal {
+			end++
+		}
+		sort.SliceStable(edits[start:end], func(i, j int) bool {
+			return edits[start+i].Op == Delete && edits[start+j].Op == Insert
+		})
+		start = end
+	}
+
+	return edits
+}
+
+// Edit script being built by divide and conquer, edits are appended from start to end
+type editScript struct {
+	a, b  []string
+	edits []Edit
+}
+
+// Append edits that turn a[aStart:aEnd] into b[bStart:bEnd]
+func (script *editScript) diff(aStart, aEnd, bStart, bEnd int) {
+	// Common prefix and suffix are equal lines, no need to search them
+	for aStart < aEnd && bStart < bEnd && script.a[aStart] == script.b[bStart] {
+		script.appendEqual(aStart, bStart)
+		aStart++
+		bStart++
+	}
+	suffix := 0
+	for aStart < aEnd-suffix && bStart < bEnd-suffix && script.a[aEnd-suffix-1] == script.b[bEnd-suffix-1] {
+		suffix++
+	}
+	aEnd -= suffix
+	bEnd -= suffix
+
+	switch {
+	case aStart == aEnd:
+		for y := bStart; y < bEnd; y++ {
+			script.edits = append(script.edits, Edit{Op: Insert, OldLine: -1, NewLine: y, Text: script.b[y]})
+		}
+	case bStart == bEnd:
+		for x := aStart; x < aEnd; x++ {
+			script.edits = append(script.edits, Edit{Op: Delete, OldLine: x, NewLine: -1, Text: script.a[x]})
+		}
+	default:
+		// Both sides are not empty and differ at both ends, so each half has fewer edits than the whole
+		x, y, u, v := script.middleSnake(aStart, aEnd, bStart, bEnd)
+		script.diff(aStart, x, bStart, y)
+		for ; x < u; x, y = x+1, y+1 {
+			script.appendEqual(x, y)
+		}
+		script.diff(u, aEnd, v, bEnd)
+	}
+
+	for i := 0; i < suffix; i++ {
+		script.appendEqual(aEnd+i, bEnd+i)
+	}
+}
+
+// Append equal line
+func (script *editScript) appendEqual(x, y int) {
+	script.edits = append(script.edits, Edit{Op: Equal, OldLine: x, NewLine: y, Text: script.a[x]})
+}
+
+// Find middle snake of shortest edit path by searching forward from start and backward from end at the same time
+// Snake goes from (x, y) to (u, v) in absolute line numbers, lines between them are equal
+func (script *editScript) middleSnake(aStart, aEnd, bStart, bEnd int) (int, int, int, int) {
+	a, b := script.a[aStart:aEnd], script.b[bStart:bEnd]
+	n, m := len(a), len(b)
+	delta := n - m
+	max := (n + m + 1) / 2
+	offset := max + 1
+
+	// forward[k] is furthest x of diagonal k from start, backward[c] is furthest x of diagonal c counted from end
+	forward := make([]int, 2*max+3)
+	backward := make([]int, 2*max+3)
+
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1] // Move down (insertion)
+			} else {
+				x = forward[offset+k-1] + 1 // Move right (deletion)
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+
+			// Odd delta can only meet backward path of previous step
+			if c := delta - k; delta%2 != 0 && c >= -(d-1) && c <= d-1 && x+backward[offset+c] >= n {
+				return aStart + startX, bStart + startY, aStart + x, bStart + y
+			}
+		}
+
+		for c := -d; c <= d; c += 2 {
+			var x int
+			if c == -d || (c != d && backward[offset+c-1] < backward[offset+c+1]) {
+				x = backward[offset+c+1]
+			} else {
+				x = backward[offset+c-1] + 1
+			}
+			y := x - c
+			startX, startY := x, y
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			backward[offset+c] = x
+
+			// Even delta meets forward path of the same step
+			if k := delta - c; delta%2 == 0 && k >= -d && k <= d && x+forward[offset+k] >= n {
+				return aStart + n - x, bStart + m - y, aStart + n - startX, bStart + m - startY
+			}
+		}
+	}
+
+	// Paths always meet before max steps, this is never reached
+	panic("diff: middle snake not found")
+}
+
+// Compute edit script between two contents
+func Lines(oldContent, newContent []byte) []Edit {
+	return Myers(SplitLines(oldContent), SplitLines(newContent))
+}
+
+// Group of edits printed with a single "@@ -a,b +c,d @@" header
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Edits    []Edit
+}
+
+// Group edit script into hunks with the given number of context lines around changes
+func Hunks(edits []Edit, context int) []Hunk {
+	var hunks []Hunk
+
+	i := 0
+	for i < len(edits) {
+		// Find next change
+		for i < len(edits) && edits[i].Op == Equal {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		// Extend hunk while the gap between changes is small enough to share context
+		end := i
+		for end < len(edits) {
+			if edits[end].Op != Equal {
+				end++
+				continue
+			}
+			next := end
+			for next < len(edits) && edits[next].Op == Equal {
+				next++
+			}
+			if next == len(edits) || next-end > 2*context {
+				end += context
+				if end > len(edits) {
+					end = len(edits)
+				}
+				break
+			}
+			end = next
+		}
+
+		oldBefore, newBefore := 0, 0
+		for _, edit := range edits[:start] {
+			if edit.Op != Insert {
+				oldBefore++
+			}
+			if edit.Op != Delete {
+				newBefore++
+			}
+		}
+
+		hunks = append(hunks, newHunk(edits[start:end], oldBefore, newBefore))
+		i = end
+	}
+
+	return hunks
+}
+
+// Build hunk and compute line ranges from its edits, oldBefore and newBefore are line counts before the hunk
+func newHunk(edits []Edit, oldBefore, newBefore int) Hunk {
+	hunk := Hunk{Edits: edits}
+
+	for _, edit := range edits {
+		if edit.Op != Insert {
+			hunk.OldLines++
+		}
+		if edit.Op != Delete {
+			hunk.NewLines++
+		}
+	}
+
+	// Line numbers in hunk header start from 1, empty range use the line before it
+	hunk.OldStart = oldBefore + 1
+	if hunk.OldLines == 0 {
+		hunk.OldStart = oldBefore
+	}
+	hunk.NewStart = newBefore + 1
+	if hunk.NewLines == 0 {
+		hunk.NewStart = newBefore
+	}
+
+	return hunk
+}
+
+// Format range of hunk header (e.g "3,4" or "3" for single line)
+func formatRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// Create unified diff body (hunks only, without file headers) between two contents
+func Unified(oldContent, newContent []byte, context int) string {
+	var sb strings.Builder
+
+	for _, hunk := range Hunks(Lines(oldContent, newContent), context) {
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", formatRange(hunk.OldStart, hunk.OldLines), formatRange(hunk.NewStart, hunk.NewLines)))
+
+		for _, edit := range hunk.Edits {
+			prefix := " "
+			if edit.Op == Insert {
+				prefix = "+"
+			} else if edit.Op == Delete {
+				prefix = "-"
+			}
+
+			sb.WriteString(prefix + edit.Text)
+			if !strings.HasSuffix(edit.Text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
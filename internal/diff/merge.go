@@ -0,0 +1,114 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package diff
+
+import (
+	"strings"
+)
+
+// Conflict markers written around conflicting lines
+const (
+	ConflictStart  = "<<<<<<<"
+	ConflictMiddle = "======="
+	ConflictEnd    = ">>>>>>>"
+)
+
+// For every line of old side, find index of the same line in new side (-1 when line is changed)
+func matchLines(edits []Edit, oldLength int) []int {
+	matches := make([]int, oldLength)
+	for i := range matches {
+		matches[i] = -1
+	}
+	for _, edit := range edits {
+		if edit.Op == Equal {
+			matches[edit.OldLine] = edit.NewLine
+		}
+	}
+	return matches
+}
+
+// Check two line lists are exactly the same
+func sameLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Three-way merge of text content (diff3), returns merged content and whether it is free of conflicts
+// Conflicting chunks are written with markers labeled by oursLabel and theirsLabel
+func Merge3(base, ours, theirs []byte, oursLabel, theirsLabel string) ([]byte, bool) {
+	baseLines := SplitLines(base)
+	oursLines := SplitLines(ours)
+	theirsLines := SplitLines(theirs)
+
+	oursMatches := matchLines(Myers(baseLines, oursLines), len(baseLines))
+	theirsMatches := matchLines(Myers(baseLines, theirsLines), len(baseLines))
+
+	var sb strings.Builder
+	clean := true
+	i, j, k := 0, 0, 0 // Current line of base, ours and theirs
+
+	for i < len(baseLines) || j < len(oursLines) || k < len(theirsLines) {
+		// Line that is unchanged in both sides can be copied as it is
+		if i < len(baseLines) && oursMatches[i] == j && theirsMatches[i] == k {
+			sb.WriteString(baseLines[i])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		// Find next base line that is unchanged in both sides, everything before it is one changed chunk
+		nextBase, nextOurs, nextTheirs := len(baseLines), len(oursLines), len(theirsLines)
+		for candidate := i; candidate < len(baseLines); candidate++ {
+			if oursMatches[candidate] >= j && theirsMatches[candidate] >= k {
+				nextBase, nextOurs, nextTheirs = candidate, oursMatches[candidate], theirsMatches[candidate]
+				break
+			}
+		}
+
+		baseChunk := baseLines[i:nextBase]
+		oursChunk := oursLines[j:nextOurs]
+		theirsChunk := theirsLines[k:nextTheirs]
+
+		switch {
+		case sameLines(oursChunk, baseChunk):
+			// Only theirs changed this chunk
+			sb.WriteString(strings.Join(theirsChunk, ""))
+		case sameLines(theirsChunk, baseChunk) || sameLines(oursChunk, theirsChunk):
+			// Only ours changed this chunk or both sides made the same change
+			sb.WriteString(strings.Join(oursChunk, ""))
+		default:
+			clean = false
+			writeConflict(&sb, oursChunk, theirsChunk, oursLabel, theirsLabel)
+		}
+
+		i, j, k = nextBase, nextOurs, nextTheirs
+	}
+
+	return []byte(sb.String()), clean
+}
+
+// Write conflicting chunk surrounded with conflict markers
+func writeConflict(sb *strings.Builder, oursChunk, theirsChunk []string, oursLabel, theirsLabel string) {
+	writeChunk := func(lines []string) {
+		for _, line := range lines {
+			sb.WriteString(line)
+		}
+		// Marker must start on its own line even when last line has no new line
+		if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString(ConflictStart + " " + oursLabel + "\n")
+	writeChunk(oursChunk)
+	sb.WriteString(ConflictMiddle + "\n")
+	writeChunk(theirsChunk)
+	sb.WriteString(ConflictEnd + " " + theirsLabel + "\n")
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package transport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Hash value used by server with no refs to still send its capabilities
+const zeroHash = "0000000000000000000000000000000000000000"
+
+// Reference advertised by remote repository
+type Ref struct {
+	Name   string
+	Hash   string
+	Peeled string // Object that annotated tag is pointing to (from "<tag>^{}" line)
+}
+
+// Refs and capabilities advertised by remote repository
+type Advertisement struct {
+	Refs         []Ref
+	Capabilities []string
+}
+
+// Check remote supports the capability (e.g side-band-64k)
+func (adv *Advertisement) HasCapability(name string) bool {
+	for _, capability := range adv.Capabilities {
+		if capability == name || strings.HasPrefix(capability, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// Get branch that remote HEAD is pointing to (from symref capability), empty string when it is unknown
+func (adv *Advertisement) HeadTarget() string {
+	for _, capability := range adv.Capabilities {
+		if target, ok := strings.CutPrefix(capability, "symref=HEAD:"); ok {
+			return target
+		}
+	}
+	return ""
+}
+
+// Find advertised ref by name
+func (adv *Advertisement) Find(name string) (Ref, bool) {
+	for _, ref := range adv.Refs {
+		if ref.Name == name {
+			return ref, true
+		}
+	}
+	return Ref{}, false
+}
+
+// Parse ref advertisement (protocol v0) until flush-pkt
+// First line carries capabilities after NUL byte (e.g "<hash> HEAD\x00side-band-64k ofs-delta\n")
+func ParseAdvertisement(r io.Reader) (*Advertisement, error) {
+	adv := &Advertisement{}
+	isFirst := true
+
+	for {
+		line, err := ReadPktLine(r)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read ref advertisement: %v", err)
+		}
+		if line == nil {
+			return adv, nil
+		}
+		line = bytes.TrimSuffix(line, []byte("\n"))
+
+		if isFirst {
+			isFirst = false
+			refPart, capabilities, ok := bytes.Cut(line, []byte{0})
+			if ok {
+				adv.Capabilities = strings.Fields(string(capabilities))
+			}
+			line = refPart
+		}
+
+		if bytes.HasPrefix(line, []byte("ERR ")) {
+			return nil, fmt.Errorf("remote error: %s", line[4:])
+		}
+
+		hashValue, name, ok := strings.Cut(string(line), " ")
+		if !ok || len(hashValue) != 40 {
+			return nil, fmt.Errorf("invalid ref advertisement line '%s'", line)
+		}
+
+		// Empty repository only advertises capabilities
+		if hashValue == zeroHash && name == "capabilities^{}" {
+			continue
+		}
+
+		// "<tag>^{}" line gives the object annotated tag is pointing to
+		if tagName, ok := strings.CutSuffix(name, "^{}"); ok {
+			for i := range adv.Refs {
+				if adv.Refs[i].Name == tagName {
+					adv.Refs[i].Peeled = hashValue
+				}
+			}
+			continue
+		}
+
+		adv.Refs = append(adv.Refs, Ref{Name: name, Hash: hashValue})
+	}
+}
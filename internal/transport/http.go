@@ -0,0 +1,103 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package transport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Transport that talks Git smart HTTP protocol (stateless, one request per round)
+type HTTPTransport struct {
+	URL    string
+	Client *http.Client
+}
+
+// Create smart HTTP transport for repository URL (e.g https://example.com/repo.git)
+func NewHTTPTransport(url string) *HTTPTransport {
+	return &HTTPTransport{URL: strings.TrimSuffix(url, "/"), Client: http.DefaultClient}
+}
+
+// Send request with headers smart HTTP servers expect, non-200 response is an error
+func (t *HTTPTransport) do(req *http.Request) (*http.Response, error) {
+	// Some servers only speak smart protocol with clients whose agent starts with "git/"
+	req.Header.Set("User-Agent", "git/"+Agent)
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unable to access '%s': server returned %s", t.URL, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// Get refs and capabilities from GET <url>/info/refs?service=git-upload-pack
+func (t *HTTPTransport) Advertise() (*Advertisement, error) {
+	req, err := http.NewRequest(http.MethodGet, t.URL+"/info/refs?service=git-upload-pack", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("Content-Type") != "application/x-git-upload-pack-advertisement" {
+		return nil, fmt.Errorf("'%s' does not support smart HTTP protocol", t.URL)
+	}
+
+	// Smart response starts with service line and flush-pkt before the advertisement
+	serviceLine, err := ReadPktLine(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read service line: %v", err)
+	}
+	if strings.TrimSuffix(string(serviceLine), "\n") != "# service=git-upload-pack" {
+		return nil, fmt.Errorf("unexpected service line '%s'", serviceLine)
+	}
+	if flush, err := ReadPktLine(resp.Body); err != nil || flush != nil {
+		return nil, fmt.Errorf("expected flush after service line")
+	}
+
+	return ParseAdvertisement(resp.Body)
+}
+
+// Nothing to release, every request uses its own connection
+func (t *HTTPTransport) Close() error {
+	return nil
+}
+
+// Request pack from POST <url>/git-upload-pack
+func (t *HTTPTransport) FetchPack(adv *Advertisement, wants []string, haves []string, progress io.Writer) ([]byte, error) {
+	capabilities := selectFetchCapabilities(adv)
+
+	var body bytes.Buffer
+	err := WriteUploadRequest(&body, wants, haves, capabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.URL+"/git-upload-pack", &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-git-upload-pack-request")
+	req.Header.Set("Accept", "application/x-git-upload-pack-result")
+
+	resp, err := t.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return ReadUploadResponse(resp.Body, usesSideBand(capabilities), progress)
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package transport
+
+import (
+	"encoding/binary"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Run git command in dir with fixed identity and no user config, output without trailing new line is returned
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=Kei-K23", "GIT_AUTHOR_EMAIL=kei@example.com", "GIT_AUTHOR_DATE=1727266964 +0630",
+		"GIT_COMMITTER_NAME=Kei-K23", "GIT_COMMITTER_EMAIL=kei@example.com", "GIT_COMMITTER_DATE=1727266964 +0630")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// Create bare repository with three commits served by git http-backend, commit hashes are returned oldest first
+func startHTTPBackend(t *testing.T) (string, []string) {
+	t.Helper()
+
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	work := filepath.Join(root, "work")
+	runGit(t, root, "init", "-q", "-b", "main", work)
+
+	var commits []string
+	for i, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		err := os.WriteFile(filepath.Join(work, name), []byte(strings.Repeat(name+"\n", i+1)), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, work, "add", name)
+		runGit(t, work, "commit", "-q", "-m", "add "+name)
+		commits = append(commits, runGit(t, work, "rev-parse", "HEAD"))
+	}
+	runGit(t, root, "clone", "-q", "--bare", work, filepath.Join(root, "repo.git"))
+
+	server := httptest.NewServer(&cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1", "GIT_CONFIG_NOSYSTEM=1"},
+	})
+	t.Cleanup(server.Close)
+
+	return server.URL + "/repo.git", commits
+}
+
+// Get number of objects from pack header
+func packObjectCount(t *testing.T, pack []byte) uint32 {
+	t.Helper()
+
+	if len(pack) < 12 || string(pack[:4]) != "PACK" {
+		t.Fatalf("response is not a pack: %q", pack)
+	}
+	return binary.BigEndian.Uint32(pack[8:12])
+}
+
+func TestHTTPFetchPack(t *testing.T) {
+	url, commits := startHTTPBackend(t)
+	transport := NewHTTPTransport(url)
+
+	adv, err := transport.Advertise()
+	if err != nil {
+		t.Fatalf("Advertise failed: %v", err)
+	}
+	head, ok := adv.Find("refs/heads/main")
+	if !ok || head.Hash != commits[2] {
+		t.Fatalf("refs/heads/main = %+v, want %s", head, commits[2])
+	}
+	if adv.HeadTarget() != "refs/heads/main" {
+		t.Errorf("HEAD target = '%s', want refs/heads/main", adv.HeadTarget())
+	}
+
+	tests := map[string]struct {
+		haves []string
+		want  uint32
+	}{
+		// Every commit has commit, tree and one new blob
+		"clone":                    {nil, 9},
+		"fetch with one have":      {commits[1:2], 3},
+		"fetch with several haves": {[]string{commits[1], commits[0]}, 3},
+		"fetch with unknown have":  {[]string{strings.Repeat("f", 40), commits[0]}, 6},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pack, err := transport.FetchPack(adv, []string{head.Hash}, test.haves, nil)
+			if err != nil {
+				t.Fatalf("FetchPack failed: %v", err)
+			}
+			if count := packObjectCount(t, pack); count != test.want {
+				t.Errorf("pack has %d objects, want %d", count, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Longest data that fits into one pkt-line (65520 bytes minus 4 bytes length prefix)
+const MaxPktLineData = 65516
+
+// Side-band channels used by upload-pack to multiplex pack data with progress and error messages
+const (
+	SideBandData     = 1
+	SideBandProgress = 2
+	SideBandError    = 3
+)
+
+// Write data as pkt-line, length prefix is 4 hex digits including the prefix itself (e.g "0009done\n")
+func WritePktLine(w io.Writer, data []byte) error {
+	if len(data) > MaxPktLineData {
+		return fmt.Errorf("pkt-line data is too long (%d bytes)", len(data))
+	}
+	_, err := fmt.Fprintf(w, "%04x%s", len(data)+4, data)
+	return err
+}
+
+// Write text as pkt-line
+func WritePktString(w io.Writer, text string) error {
+	return WritePktLine(w, []byte(text))
+}
+
+// Write flush-pkt ("0000") that ends a list of pkt-lines
+func WriteFlush(w io.Writer) error {
+	_, err := io.WriteString(w, "0000")
+	return err
+}
+
+// Read one pkt-line, nil data is returned for flush-pkt
+func ReadPktLine(r io.Reader) ([]byte, error) {
+	var lengthBuf [4]byte
+	if _, err := io.ReadFull(r, lengthBuf[:]); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	length, err := strconv.ParseUint(string(lengthBuf[:]), 16, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pkt-line length '%s'", lengthBuf[:])
+	}
+	if length == 0 {
+		return nil, nil
+	}
+	if length < 4 {
+		return nil, errors.New("invalid pkt-line length")
+	}
+
+	data := make([]byte, length-4)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package transport
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+)
+
+// Transport that runs upload-pack or receive-pack as child process and talks to it through stdin and stdout (used for local repositories)
+type ProcessTransport struct {
+	Command []string
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// Create transport that runs git-go upload-pack for local repository path
+func NewLocalTransport(path string) (*ProcessTransport, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return &ProcessTransport{Command: []string{executable, "upload-pack", path}}, nil
+}
+
+// Create transport that runs git-go receive-pack for local repository path
+func NewLocalPushTransport(path string) (*ProcessTransport, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return &ProcessTransport{Command: []string{executable, "receive-pack", path}}, nil
+}
+
+// Start child process, its stderr goes to our stderr so remote errors are visible
+func (t *ProcessTransport) start() error {
+	t.cmd = exec.Command(t.Command[0], t.Command[1:]...)
+	t.cmd.Stderr = os.Stderr
+
+	stdin, err := t.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := t.cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	t.stdin, t.stdout = stdin, bufio.NewReader(stdout)
+
+	return t.cmd.Start()
+}
+
+// Get refs and capabilities from advertisement that child process writes when it starts
+func (t *ProcessTransport) Advertise() (*Advertisement, error) {
+	if t.cmd != nil {
+		return nil, fmt.Errorf("%s is already started", t.Command[1])
+	}
+	if err := t.start(); err != nil {
+		return nil, err
+	}
+
+	adv, err := ParseAdvertisement(t.stdout)
+	if err != nil {
+		t.Close()
+		return nil, err
+	}
+	return adv, nil
+}
+
+// Send request to running upload-pack and read pack from its response
+func (t *ProcessTransport) FetchPack(adv *Advertisement, wants []string, haves []string, progress io.Writer) ([]byte, error) {
+	if t.cmd == nil {
+		return nil, fmt.Errorf("%s is not started", t.Command[1])
+	}
+
+	capabilities := selectFetchCapabilities(adv)
+	err := WriteUploadRequest(t.stdin, wants, haves, capabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	pack, err := ReadUploadResponse(t.stdout, usesSideBand(capabilities), progress)
+	if err != nil {
+		return nil, err
+	}
+
+	return pack, t.wait()
+}
+
+// Send update commands and pack to running receive-pack, then read its report
+// Pack is not sent when every update deletes a ref
+func (t *ProcessTransport) SendPack(adv *Advertisement, updates []RefUpdate, pack []byte) (*PushReport, error) {
+	if t.cmd == nil {
+		return nil, fmt.Errorf("%s is not started", t.Command[1])
+	}
+
+	capabilities := selectPushCapabilities(adv)
+	err := WriteUpdateCommands(t.stdin, updates, capabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, update := range updates {
+		if !update.IsDelete() {
+			if _, err := t.stdin.Write(pack); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	// Remote without report-status doesn't tell result, so every update is assumed to be done
+	report := &PushReport{RefErrors: make(map[string]string)}
+	if slices.Contains(capabilities, "report-status") {
+		report, err = ReadPushReport(t.stdout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, t.wait()
+}
+
+// Tell child process we don't want or send anything when request is not sent yet, then wait for it to exit
+func (t *ProcessTransport) Close() error {
+	if t.cmd == nil || t.cmd.ProcessState != nil {
+		return nil
+	}
+	WriteFlush(t.stdin)
+	return t.wait()
+}
+
+// Close stdin and wait for child process to exit
+func (t *ProcessTransport) wait() error {
+	t.stdin.Close()
+	if err := t.cmd.Wait(); err != nil {
+		return fmt.Errorf("%s failed: %v", t.Command[1], err)
+	}
+	return nil
+}
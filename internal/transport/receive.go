@@ -0,0 +1,174 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package transport
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Ref update sent to receive-pack, empty hash value means ref doesn't exist (before create or after delete)
+type RefUpdate struct {
+	Name    string
+	OldHash string
+	NewHash string
+}
+
+// Check update deletes the ref
+func (update RefUpdate) IsDelete() bool {
+	return update.NewHash == ""
+}
+
+// Result of push reported by receive-pack
+type PushReport struct {
+	UnpackError string            // Empty when pack is stored without error
+	RefErrors   map[string]string // Reason for every ref that is not updated
+}
+
+// Connection to remote repository that objects can be pushed to
+type PushTransport interface {
+	// Get refs and capabilities advertised by receive-pack of remote
+	Advertise() (*Advertisement, error)
+	// Send ref updates together with pack containing objects remote needs for them
+	SendPack(adv *Advertisement, updates []RefUpdate, pack []byte) (*PushReport, error)
+	// Release connection, must be called even when nothing is pushed
+	Close() error
+}
+
+// Open push transport for remote URL, only local repositories (file:// or path) can receive push
+func OpenPush(url string) (PushTransport, error) {
+	if IsLocalURL(url) {
+		return NewLocalPushTransport(strings.TrimPrefix(url, "file://"))
+	}
+	return nil, fmt.Errorf("pushing to '%s' is not supported, only local repositories can receive push", url)
+}
+
+// Choose capabilities to request for push, only those advertised by remote are used
+func selectPushCapabilities(adv *Advertisement) []string {
+	var capabilities []string
+	for _, capability := range []string{"report-status", "ofs-delta"} {
+		if adv.HasCapability(capability) {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	if adv.HasCapability("agent") {
+		capabilities = append(capabilities, "agent="+Agent)
+	}
+	return capabilities
+}
+
+// Format hash value for update command, missing ref is sent as zero hash value
+func commandHash(hashValue string) string {
+	if hashValue == "" {
+		return zeroHash
+	}
+	return hashValue
+}
+
+// Write update commands ("<old> <new> <ref>"), capabilities are sent after NUL byte of first command
+func WriteUpdateCommands(w io.Writer, updates []RefUpdate, capabilities []string) error {
+	for i, update := range updates {
+		line := fmt.Sprintf("%s %s %s", commandHash(update.OldHash), commandHash(update.NewHash), update.Name)
+		if i == 0 {
+			line += "\x00" + strings.Join(capabilities, " ")
+		}
+		if err := WritePktString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	return WriteFlush(w)
+}
+
+// Read update commands of receive-pack request until flush-pkt, capabilities come from first command
+func ReadUpdateCommands(r io.Reader) ([]RefUpdate, []string, error) {
+	var updates []RefUpdate
+	var capabilities []string
+
+	for {
+		line, err := ReadPktLine(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if line == nil {
+			return updates, capabilities, nil
+		}
+
+		command, capabilityList, hasCapabilities := strings.Cut(strings.TrimSuffix(string(line), "\n"), "\x00")
+		fields := strings.Fields(command)
+		if len(fields) != 3 || len(fields[0]) != 40 || len(fields[1]) != 40 {
+			return nil, nil, fmt.Errorf("protocol error: expected update command, got '%s'", command)
+		}
+		if hasCapabilities && len(updates) == 0 {
+			capabilities = strings.Fields(capabilityList)
+		}
+
+		update := RefUpdate{Name: fields[2], OldHash: fields[0], NewHash: fields[1]}
+		if update.OldHash == zeroHash {
+			update.OldHash = ""
+		}
+		if update.NewHash == zeroHash {
+			update.NewHash = ""
+		}
+		updates = append(updates, update)
+	}
+}
+
+// Write report-status response ("unpack ok", then "ok <ref>" or "ng <ref> <reason>" for every update)
+func WritePushReport(w io.Writer, report *PushReport, updates []RefUpdate) error {
+	unpackStatus := "ok"
+	if report.UnpackError != "" {
+		unpackStatus = report.UnpackError
+	}
+	if err := WritePktString(w, "unpack "+unpackStatus+"\n"); err != nil {
+		return err
+	}
+
+	for _, update := range updates {
+		line := "ok " + update.Name
+		if reason, ok := report.RefErrors[update.Name]; ok {
+			line = "ng " + update.Name + " " + reason
+		}
+		if err := WritePktString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return WriteFlush(w)
+}
+
+// Read report-status response of receive-pack
+func ReadPushReport(r io.Reader) (*PushReport, error) {
+	report := &PushReport{RefErrors: make(map[string]string)}
+
+	line, err := ReadPktLine(r)
+	if err != nil {
+		return nil, err
+	}
+	unpackStatus, ok := strings.CutPrefix(strings.TrimSuffix(string(line), "\n"), "unpack ")
+	if !ok {
+		return nil, fmt.Errorf("protocol error: expected unpack status, got '%s'", strings.TrimSpace(string(line)))
+	}
+	if unpackStatus != "ok" {
+		report.UnpackError = unpackStatus
+	}
+
+	for {
+		line, err := ReadPktLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if line == nil {
+			return report, nil
+		}
+
+		status := strings.TrimSuffix(string(line), "\n")
+		if rest, ok := strings.CutPrefix(status, "ng "); ok {
+			name, reason, _ := strings.Cut(rest, " ")
+			report.RefErrors[name] = reason
+		} else if !strings.HasPrefix(status, "ok ") {
+			return nil, fmt.Errorf("protocol error: unexpected ref status '%s'", status)
+		}
+	}
+}
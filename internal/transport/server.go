@@ -0,0 +1,97 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package transport
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Write ref advertisement (protocol v0), capabilities are sent after NUL byte of first line
+func WriteAdvertisement(w io.Writer, refs []Ref, capabilities []string) error {
+	capabilityList := strings.Join(capabilities, " ")
+
+	// Repository without refs still has to tell its capabilities
+	if len(refs) == 0 {
+		err := WritePktString(w, fmt.Sprintf("%s capabilities^{}\x00%s\n", zeroHash, capabilityList))
+		if err != nil {
+			return err
+		}
+		return WriteFlush(w)
+	}
+
+	for i, ref := range refs {
+		line := fmt.Sprintf("%s %s", ref.Hash, ref.Name)
+		if i == 0 {
+			line += "\x00" + capabilityList
+		}
+		if err := WritePktString(w, line+"\n"); err != nil {
+			return err
+		}
+		if ref.Peeled != "" {
+			if err := WritePktString(w, fmt.Sprintf("%s %s^{}\n", ref.Peeled, ref.Name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return WriteFlush(w)
+}
+
+// Read want lines of upload-pack request until flush-pkt, capabilities come from first want line
+// No wants means client doesn't need anything and request is over
+func ReadWants(r io.Reader) ([]string, []string, error) {
+	var wants, capabilities []string
+
+	for {
+		line, err := ReadPktLine(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if line == nil {
+			return wants, capabilities, nil
+		}
+
+		fields := strings.Fields(string(line))
+		if len(fields) < 2 || fields[0] != "want" || len(fields[1]) != 40 {
+			return nil, nil, fmt.Errorf("protocol error: expected want line, got '%s'", strings.TrimSpace(string(line)))
+		}
+		if len(wants) == 0 {
+			capabilities = fields[2:]
+		}
+		wants = append(wants, fields[1])
+	}
+}
+
+// Write pack data to client, data is split into side-band pkt-lines when side-band capability is used
+func WritePackData(w io.Writer, pack []byte, capabilities []string) error {
+	// side-band allows 1000 bytes per pkt-line and side-band-64k allows full pkt-line, both need one byte for channel
+	chunkSize := 0
+	for _, capability := range capabilities {
+		switch capability {
+		case "side-band-64k":
+			chunkSize = MaxPktLineData - 1
+		case "side-band":
+			if chunkSize == 0 {
+				chunkSize = 1000 - 4 - 1
+			}
+		}
+	}
+
+	if chunkSize == 0 {
+		_, err := w.Write(pack)
+		return err
+	}
+
+	for len(pack) > 0 {
+		n := min(len(pack), chunkSize)
+		if err := WritePktLine(w, append([]byte{SideBandData}, pack[:n]...)); err != nil {
+			return err
+		}
+		pack = pack[n:]
+	}
+
+	return WriteFlush(w)
+}
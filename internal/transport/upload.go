@@ -0,0 +1,200 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Agent name sent to remote in agent capability
+const Agent = "git-go/1.0"
+
+// Connection to remote repository that objects can be fetched from
+type Transport interface {
+	// Get refs and capabilities advertised by upload-pack of remote
+	Advertise() (*Advertisement, error)
+	// Request pack containing wanted objects, haves are commits we already have so remote can leave out their history
+	FetchPack(adv *Advertisement, wants []string, haves []string, progress io.Writer) ([]byte, error)
+	// Release connection, must be called even when nothing is fetched
+	Close() error
+}
+
+// Check remote URL is a path of local repository instead of network URL
+func IsLocalURL(url string) bool {
+	return !strings.Contains(url, "://") || strings.HasPrefix(url, "file://")
+}
+
+// Open transport for remote URL (http://, https://, file:// or local path)
+func Open(url string) (Transport, error) {
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		return NewHTTPTransport(url), nil
+	}
+	if IsLocalURL(url) {
+		return NewLocalTransport(strings.TrimPrefix(url, "file://"))
+	}
+	return nil, fmt.Errorf("unsupported remote URL '%s'", url)
+}
+
+// Choose capabilities to request, only those advertised by remote are used
+func selectFetchCapabilities(adv *Advertisement) []string {
+	var capabilities []string
+	if adv.HasCapability("side-band-64k") {
+		capabilities = append(capabilities, "side-band-64k")
+	} else if adv.HasCapability("side-band") {
+		capabilities = append(capabilities, "side-band")
+	}
+	for _, capability := range []string{"ofs-delta", "include-tag"} {
+		if adv.HasCapability(capability) {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	if adv.HasCapability("agent") {
+		capabilities = append(capabilities, "agent="+Agent)
+	}
+	return capabilities
+}
+
+// Write upload-pack request, capabilities are sent with first want line and request always ends with done
+func WriteUploadRequest(w io.Writer, wants []string, haves []string, capabilities []string) error {
+	if len(wants) == 0 {
+		return errors.New("upload request needs at least one want")
+	}
+
+	for i, want := range wants {
+		line := "want " + want
+		if i == 0 && len(capabilities) > 0 {
+			line += " " + strings.Join(capabilities, " ")
+		}
+		if err := WritePktString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	if err := WriteFlush(w); err != nil {
+		return err
+	}
+
+	for _, have := range haves {
+		if err := WritePktString(w, "have "+have+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return WritePktString(w, "done\n")
+}
+
+// Read upload-pack response, acknowledgement lines are skipped and pack data is returned
+// Progress messages of remote are written to progress writer when side-band is used
+func ReadUploadResponse(r io.Reader, sideBand bool, progress io.Writer) ([]byte, error) {
+	reader := bufio.NewReader(r)
+	var pack bytes.Buffer
+	atLineStart := true // Progress message may be split over several pkt-lines
+
+	// Remote may send ACK for every common have (even without multi_ack), so acknowledgement only ends when pack starts
+	for {
+		if !sideBand {
+			if start, err := reader.Peek(4); err == nil && string(start) == "PACK" {
+				return io.ReadAll(reader)
+			}
+		}
+
+		line, err := ReadPktLine(reader)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read upload-pack response: %v", err)
+		}
+		text := strings.TrimSuffix(string(line), "\n")
+
+		if strings.HasPrefix(text, "ERR ") {
+			return nil, fmt.Errorf("remote error: %s", text[4:])
+		}
+		if line == nil || text == "NAK" || strings.HasPrefix(text, "ACK ") ||
+			strings.HasPrefix(text, "shallow ") || strings.HasPrefix(text, "unshallow ") {
+			continue
+		}
+		if !sideBand || len(line) == 0 || line[0] > SideBandError {
+			return nil, fmt.Errorf("unexpected upload-pack response '%s'", text)
+		}
+
+		// First side-band line of pack stream
+		done, err := readSideBandLine(line, &pack, progress, &atLineStart)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return pack.Bytes(), nil
+		}
+		break
+	}
+
+	for {
+		line, err := ReadPktLine(reader)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read pack data: %v", err)
+		}
+		done, err := readSideBandLine(line, &pack, progress, &atLineStart)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return pack.Bytes(), nil
+		}
+	}
+}
+
+// Handle one side-band pkt-line, pack data goes to pack and progress to progress writer
+// Flush-pkt ends the stream and true is returned
+func readSideBandLine(line []byte, pack *bytes.Buffer, progress io.Writer, atLineStart *bool) (bool, error) {
+	if line == nil {
+		return true, nil
+	}
+	if len(line) == 0 {
+		return false, nil
+	}
+
+	switch line[0] {
+	case SideBandData:
+		pack.Write(line[1:])
+	case SideBandProgress:
+		if progress != nil {
+			*atLineStart = writeProgress(progress, line[1:], *atLineStart)
+		}
+	case SideBandError:
+		return false, fmt.Errorf("remote error: %s", strings.TrimSpace(string(line[1:])))
+	default:
+		return false, fmt.Errorf("unknown side-band channel %d", line[0])
+	}
+	return false, nil
+}
+
+// Write progress message of remote with "remote: " in front of every line, returns whether next write starts new line
+// Lines end with "\n" or with "\r" when remote redraws the same line (e.g percentage)
+func writeProgress(w io.Writer, message []byte, atLineStart bool) bool {
+	for len(message) > 0 {
+		end := bytes.IndexAny(message, "\r\n") + 1
+		if end == 0 {
+			end = len(message)
+		}
+		if atLineStart {
+			io.WriteString(w, "remote: ")
+		}
+		w.Write(message[:end])
+		atLineStart = message[end-1] == '\r' || message[end-1] == '\n'
+		message = message[end:]
+	}
+	return atLineStart
+}
+
+// Check side-band is used after capabilities are selected
+func usesSideBand(capabilities []string) bool {
+	for _, capability := range capabilities {
+		if capability == "side-band" || capability == "side-band-64k" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package transport
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Build upload-pack response from acknowledgement lines followed by pack data
+func uploadResponse(t *testing.T, acks []string, sideBand bool, pack string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	for _, ack := range acks {
+		if err := WritePktString(&buf, ack+"\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !sideBand {
+		buf.WriteString(pack)
+		return buf.Bytes()
+	}
+
+	WritePktLine(&buf, append([]byte{SideBandProgress}, "Counting objects: 3, done.\n"...))
+	WritePktLine(&buf, append([]byte{SideBandData}, pack[:4]...))
+	WritePktLine(&buf, append([]byte{SideBandData}, pack[4:]...))
+	WriteFlush(&buf)
+	return buf.Bytes()
+}
+
+func TestReadUploadResponse(t *testing.T) {
+	const pack = "PACK\x00\x00\x00\x02\x00\x00\x00\x00"
+	common := strings.Repeat("a", 40)
+	other := strings.Repeat("b", 40)
+
+	tests := map[string][]string{
+		"no common commit":           {"NAK"},
+		"single ACK":                 {"ACK " + common},
+		"ACK for every common have":  {"ACK " + common, "ACK " + other},
+		"multi_ack before final ACK": {"ACK " + common + " continue", "ACK " + other + " common", "NAK", "ACK " + other},
+		"shallow lines":              {"shallow " + common, "NAK"},
+	}
+	for name, acks := range tests {
+		for _, sideBand := range []bool{false, true} {
+			var progress bytes.Buffer
+			got, err := ReadUploadResponse(bytes.NewReader(uploadResponse(t, acks, sideBand, pack)), sideBand, &progress)
+			if err != nil {
+				t.Errorf("%s (side-band %v): %v", name, sideBand, err)
+				continue
+			}
+			if string(got) != pack {
+				t.Errorf("%s (side-band %v): got pack %q, want %q", name, sideBand, got, pack)
+			}
+			if sideBand && progress.String() != "remote: Counting objects: 3, done.\n" {
+				t.Errorf("%s: got progress %q", name, progress.String())
+			}
+		}
+	}
+}
+
+func TestReadUploadResponseErrors(t *testing.T) {
+	var remoteErr bytes.Buffer
+	WritePktString(&remoteErr, "ERR upload-pack: not our ref\n")
+
+	var sideBandErr bytes.Buffer
+	WritePktString(&sideBandErr, "NAK\n")
+	WritePktLine(&sideBandErr, append([]byte{SideBandError}, "fatal: out of memory\n"...))
+
+	var badChannel bytes.Buffer
+	WritePktString(&badChannel, "NAK\n")
+	WritePktLine(&badChannel, []byte{9, 'x'})
+
+	tests := map[string]struct {
+		response []byte
+		sideBand bool
+	}{
+		"remote error":       {remoteErr.Bytes(), true},
+		"side-band error":    {sideBandErr.Bytes(), true},
+		"unknown channel":    {badChannel.Bytes(), true},
+		"truncated response": {[]byte("0008NAK\n00"), true},
+		"garbage":            {[]byte("0009hello\n"), false},
+	}
+	for name, test := range tests {
+		if _, err := ReadUploadResponse(bytes.NewReader(test.response), test.sideBand, nil); err == nil {
+			t.Errorf("%s: ReadUploadResponse succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Convert list of index entries to map with file path as key
+func EntriesToMap(entries []IndexEntry) map[string]IndexEntry {
+	entryMap := make(map[string]IndexEntry, len(entries))
+	for _, entry := range entries {
+		entryMap[entry.Path] = entry
+	}
+	return entryMap
+}
+
+// Sort index entries by file path and merge stage (index file and tree objects always store sorted entries)
+func SortEntries(entries []IndexEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].Stage() < entries[j].Stage()
+	})
+}
+
+// Write blob content of the entry to working tree file
+func WriteWorkingFile(entry IndexEntry) error {
+	content, err := ReadBlobObject(entry.Hash)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(entry.Path), 0755)
+	if err != nil {
+		return err
+	}
+
+	// Existing symlink is replaced, writing through it would change the file it points to
+	if fileInfo, err := os.Lstat(entry.Path); err == nil && fileInfo.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(entry.Path); err != nil {
+			return err
+		}
+	}
+
+	// Blob of symlink stores its target path
+	if entry.Mode == "120000" {
+		if _, err := os.Lstat(entry.Path); err == nil {
+			if err := os.Remove(entry.Path); err != nil {
+				return err
+			}
+		}
+		return os.Symlink(string(content), entry.Path)
+	}
+
+	var perm os.FileMode = 0644
+	if entry.Mode == "100755" {
+		perm = 0755
+	}
+
+	err = os.WriteFile(entry.Path, content, perm)
+	if err != nil {
+		return err
+	}
+
+	// WriteFile doesn't change permission of already existing file
+	return os.Chmod(entry.Path, perm)
+}
+
+// Remove working tree file together with parent folders that become empty
+func RemoveWorkingFile(path string) error {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// Clean up empty parent folders, os.Remove fails for non-empty folder
+	dir := filepath.Dir(path)
+	for dir != "." && dir != string(filepath.Separator) {
+		if os.Remove(dir) != nil {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	return nil
+}
+
+// Move working tree and index from HEAD commit to target commit
+// Without force, local changes of files that differ between both commits make checkout fail
+func CheckoutCommit(targetCommit string, force bool) error {
+	headFiles := EntriesToMap(GetCommitFiles(GetCurrentCommit()))
+	targetEntries := GetCommitFiles(targetCommit)
+	targetFiles := EntriesToMap(targetEntries)
+
+	indexEntries, err := ReadIndexFile()
+	if err != nil {
+		return err
+	}
+	if unmergedPaths := UnmergedPaths(indexEntries); len(unmergedPaths) > 0 && !force {
+		return fmt.Errorf("you need to resolve your current index first:\n\t%s", strings.Join(unmergedPaths, "\n\t"))
+	}
+	indexFiles := EntriesToMap(indexEntries)
+
+	// Collect every path known by HEAD, target or staging area
+	pathSet := make(map[string]bool)
+	for _, files := range []map[string]IndexEntry{headFiles, targetFiles, indexFiles} {
+		for path := range files {
+			pathSet[path] = true
+		}
+	}
+	var paths []string
+	for path := range pathSet {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var changedPaths, localChanges, untrackedFiles []string
+	for _, path := range paths {
+		headEntry, inHead := headFiles[path]
+		targetEntry, inTarget := targetFiles[path]
+		indexEntry, inIndex := indexFiles[path]
+		workingHash, workingErr := HashWorkingFile(path)
+		inWorking := workingErr == nil
+
+		if force {
+			// Skip file that is already same as target
+			if inTarget && inIndex && indexEntry.SameContent(targetEntry) && workingHash == targetEntry.Hash {
+				continue
+			}
+			changedPaths = append(changedPaths, path)
+			continue
+		}
+
+		// File is same in both commits, so keep whatever local changes it has
+		if inHead == inTarget && headEntry.SameContent(targetEntry) {
+			continue
+		}
+
+		// File is already removed from staging area and target doesn't have it either
+		if !inIndex && !inTarget {
+			continue
+		}
+
+		// Staging area already matches target
+		if inIndex && inTarget && indexEntry.Hash == targetEntry.Hash && (!inWorking || workingHash == targetEntry.Hash) {
+			changedPaths = append(changedPaths, path)
+			continue
+		}
+
+		if inIndex != inHead || indexEntry.Hash != headEntry.Hash {
+			// Staged changes would be lost
+			localChanges = append(localChanges, path)
+		} else if inIndex && inWorking && workingHash != indexEntry.Hash {
+			// Unstaged changes would be lost
+			localChanges = append(localChanges, path)
+		} else if !inIndex && inWorking && inTarget && workingHash != targetEntry.Hash {
+			// Untracked file would be replaced by file from target commit
+			untrackedFiles = append(untrackedFiles, path)
+		}
+
+		changedPaths = append(changedPaths, path)
+	}
+
+	// Files and folders in the way of target files are checked before anything is changed
+	if !force {
+		removing := make(map[string]bool)
+		for _, path := range changedPaths {
+			if _, ok := targetFiles[path]; !ok {
+				removing[path] = true
+			}
+		}
+		reported := make(map[string]bool)
+		for _, path := range changedPaths {
+			if _, ok := targetFiles[path]; !ok {
+				continue
+			}
+			for _, blocker := range blockingPaths(path, removing) {
+				if !reported[blocker] {
+					reported[blocker] = true
+					untrackedFiles = append(untrackedFiles, blocker)
+				}
+			}
+		}
+	}
+
+	if len(localChanges) > 0 || len(untrackedFiles) > 0 {
+		var sb strings.Builder
+		if len(localChanges) > 0 {
+			sb.WriteString("Your local changes to the following files would be overwritten:\n")
+			for _, path := range localChanges {
+				sb.WriteString("\t" + path + "\n")
+			}
+		}
+		if len(untrackedFiles) > 0 {
+			sb.WriteString("The following untracked working tree files would be overwritten:\n")
+			for _, path := range untrackedFiles {
+				sb.WriteString("\t" + path + "\n")
+			}
+		}
+		sb.WriteString("Please commit your changes before you switch branches.")
+		return errors.New(sb.String())
+	}
+
+	// Update working tree files, removed files go first so their folders can be replaced by files (and the other way)
+	for _, path := range changedPaths {
+		if _, ok := targetFiles[path]; !ok {
+			if err := RemoveWorkingFile(path); err != nil {
+				return err
+			}
+		}
+	}
+	for _, path := range changedPaths {
+		if targetEntry, ok := targetFiles[path]; ok {
+			if err := clearWorkingPath(path); err != nil {
+				return err
+			}
+			if err := WriteWorkingFile(targetEntry); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Rebuild staging area to match target commit, stat data is cached for files that are written
+	if force {
+		newEntries := make([]IndexEntry, 0, len(targetEntries))
+		for _, targetEntry := range targetEntries {
+			newEntries = append(newEntries, RefreshEntryStat(targetEntry))
+		}
+		return WriteIndexFile(newEntries)
+	}
+
+	for _, path := range changedPaths {
+		if targetEntry, ok := targetFiles[path]; ok {
+			indexFiles[path] = RefreshEntryStat(targetEntry)
+		} else {
+			delete(indexFiles, path)
+		}
+	}
+
+	newEntries := make([]IndexEntry, 0, len(indexFiles))
+	for _, entry := range indexFiles {
+		newEntries = append(newEntries, entry)
+	}
+	SortEntries(newEntries)
+
+	return WriteIndexFile(newEntries)
+}
+
+// Get working tree files that would be lost when file is written to path, files that checkout removes anyway are left out
+// Folder at path blocks with every file inside it and file at parent folder path blocks too (e.g "a" for "a/x")
+func blockingPaths(path string, removing map[string]bool) []string {
+	var blockers []string
+	for dir := filepath.Dir(path); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		if info, err := os.Lstat(dir); err == nil && !info.IsDir() && !removing[filepath.ToSlash(dir)] {
+			blockers = append(blockers, filepath.ToSlash(dir))
+		}
+	}
+
+	if info, err := os.Lstat(path); err == nil && info.IsDir() {
+		filepath.WalkDir(path, func(filePath string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && !removing[filepath.ToSlash(filePath)] {
+				blockers = append(blockers, filepath.ToSlash(filePath))
+			}
+			return nil
+		})
+	}
+	return blockers
+}
+
+// Remove folder at path and files at its parent folder paths, so file can be written to path
+func clearWorkingPath(path string) error {
+	for dir := filepath.Dir(path); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		if info, err := os.Lstat(dir); err == nil && !info.IsDir() {
+			if err := os.Remove(dir); err != nil {
+				return err
+			}
+		}
+	}
+
+	if info, err := os.Lstat(path); err == nil && info.IsDir() {
+		return os.RemoveAll(path)
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Kei-K23/git-go/pkg/object"
+)
+
+// Parsed commit object, a merge commit has more than one parent
+type Commit struct {
+	Hash      string
+	Tree      string
+	Parents   []string
+	Author    Signature
+	Committer Signature
+	Message   string
+}
+
+// Parse commit object content with pkg/object, commits written by older git-go versions are parsed leniently
+func ParseCommit(content []byte) (Commit, error) {
+	parsed, err := object.ParseCommit(content)
+	if err != nil {
+		return parseLegacyCommit(content)
+	}
+
+	commit := Commit{
+		Tree:      parsed.Tree.String(),
+		Author:    parsed.Author,
+		Committer: parsed.Committer,
+		Message:   parsed.Message,
+	}
+	for _, parent := range parsed.Parents {
+		commit.Parents = append(commit.Parents, parent.String())
+	}
+
+	return commit, nil
+}
+
+// Parse commit that strict parser rejects (e.g RFC3339 dates written by older git-go versions), unknown headers are ignored
+func parseLegacyCommit(content []byte) (Commit, error) {
+	var commit Commit
+
+	headers, message, _ := strings.Cut(string(content), "\n\n")
+	commit.Message = message
+
+	for _, line := range strings.Split(headers, "\n") {
+		// Continuation line of multi-line header (e.g gpgsig) starts with space
+		if line == "" || strings.HasPrefix(line, " ") {
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			commit.Tree = value
+		case "parent":
+			commit.Parents = append(commit.Parents, value)
+		case "author", "committer":
+			signature, err := ParseSignature(value)
+			if err != nil {
+				return Commit{}, fmt.Errorf("invalid %s line: %v", key, err)
+			}
+			if key == "author" {
+				commit.Author = signature
+			} else {
+				commit.Committer = signature
+			}
+		}
+	}
+
+	if commit.Tree == "" {
+		return Commit{}, errors.New("commit is missing tree")
+	}
+
+	return commit, nil
+}
+
+// Encode commit to commit object content with pkg/object, message always ends with new line
+func EncodeCommit(commit Commit) ([]byte, error) {
+	encoded := object.Commit{Author: commit.Author, Committer: commit.Committer, Message: commit.Message}
+	if !strings.HasSuffix(encoded.Message, "\n") {
+		encoded.Message += "\n"
+	}
+
+	var err error
+	encoded.Tree, err = object.ParseObjectID(commit.Tree)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit tree: %v", err)
+	}
+	for _, parent := range commit.Parents {
+		parentID, err := object.ParseObjectID(parent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit parent: %v", err)
+		}
+		encoded.Parents = append(encoded.Parents, parentID)
+	}
+
+	return encoded.Encode()
+}
+
+// Read and parse commit object
+func ReadCommit(hashValue string) (Commit, error) {
+	objType, content, err := ReadObject(hashValue)
+	if err != nil {
+		return Commit{}, err
+	}
+	if objType != CommitObject {
+		return Commit{}, fmt.Errorf("object '%s' is a %s, not a commit", hashValue, objType)
+	}
+
+	commit, err := ParseCommit(content)
+	if err != nil {
+		return Commit{}, fmt.Errorf("bad commit '%s': %v", hashValue, err)
+	}
+	commit.Hash = hashValue
+
+	return commit, nil
+}
+
+// Store commit object and return its hash value
+func WriteCommit(commit Commit) (string, error) {
+	content, err := EncodeCommit(commit)
+	if err != nil {
+		return "", err
+	}
+	return WriteObject(CommitObject, content)
+}
+
+// Get first line of commit message (e.g to show in one line output)
+func (commit Commit) Subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+	return subject
+}
+
+// Check ancestor commit is reachable from descendant commit by following parents (a commit is ancestor of itself)
+func IsAncestor(ancestor string, descendant string) (bool, error) {
+	visited := make(map[string]bool)
+	queue := []string{descendant}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == ancestor {
+			return true, nil
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+
+		commit, err := ReadCommit(current)
+		if err != nil {
+			return false, err
+		}
+		queue = append(queue, commit.Parents...)
+	}
+
+	return false, nil
+}
+
+// Find best common ancestor of two commits, empty string is returned when histories are unrelated
+func MergeBase(first string, second string) (string, error) {
+	// Collect every ancestor of first commit
+	firstAncestors := make(map[string]bool)
+	queue := []string{first}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if firstAncestors[current] {
+			continue
+		}
+		firstAncestors[current] = true
+
+		commit, err := ReadCommit(current)
+		if err != nil {
+			return "", err
+		}
+		queue = append(queue, commit.Parents...)
+	}
+
+	// Walk second commit history and stop at commits shared with first commit
+	var candidates []Commit
+	visited := make(map[string]bool)
+	queue = []string{second}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+
+		commit, err := ReadCommit(current)
+		if err != nil {
+			return "", err
+		}
+		if firstAncestors[current] {
+			candidates = append(candidates, commit)
+			continue
+		}
+		queue = append(queue, commit.Parents...)
+	}
+
+	// Drop candidates that are ancestors of other candidates, they are never the best common ancestor
+	var bestCandidates []Commit
+	for _, candidate := range candidates {
+		isRedundant := false
+		for _, other := range candidates {
+			if other.Hash == candidate.Hash {
+				continue
+			}
+			if isAncestor, err := IsAncestor(candidate.Hash, other.Hash); err != nil {
+				return "", err
+			} else if isAncestor {
+				isRedundant = true
+				break
+			}
+		}
+		if !isRedundant {
+			bestCandidates = append(bestCandidates, candidate)
+		}
+	}
+
+	if len(bestCandidates) == 0 {
+		return "", nil
+	}
+
+	// Prefer the newest one when there are several best common ancestors (criss-cross merge)
+	sort.SliceStable(bestCandidates, func(i, j int) bool {
+		return bestCandidates[i].Committer.When.After(bestCandidates[j].Committer.When)
+	})
+
+	return bestCandidates[0].Hash, nil
+}
+
+// Get commits reachable from head but not from upstream, parents come before their children (e.g commits replayed by rebase)
+func CommitsBetween(upstream string, head string) ([]Commit, error) {
+	excluded := make(map[string]bool)
+	queue := []string{upstream}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == "" || excluded[current] {
+			continue
+		}
+		excluded[current] = true
+
+		commit, err := ReadCommit(current)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, commit.Parents...)
+	}
+
+	// Depth first walk adds commit after all of its parents
+	var commits []Commit
+	visited := make(map[string]bool)
+	var visit func(hashValue string) error
+	visit = func(hashValue string) error {
+		if excluded[hashValue] || visited[hashValue] {
+			return nil
+		}
+		visited[hashValue] = true
+
+		commit, err := ReadCommit(hashValue)
+		if err != nil {
+			return err
+		}
+		for _, parent := range commit.Parents {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		commits = append(commits, commit)
+		return nil
+	}
+
+	err := visit(head)
+	return commits, err
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	testTreeHash   = "4b825dc642cb6eb9a60e54bf8d69288fbee4904b"
+	testParentHash = "20b94005ce5cc553525322d91b8eb3c9b7c79532"
+)
+
+func TestParseCommit(t *testing.T) {
+	content := "tree " + testTreeHash + "\n" +
+		"parent " + testParentHash + "\n" +
+		"author Kei-K23 <arkar.dev.kei@gmail.com> 1727266964 +0630\n" +
+		"committer Other <other@example.com> 1727270000 -0000\n\nSubject line\n\nBody\n"
+
+	commit, err := ParseCommit([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseCommit failed: %v", err)
+	}
+	if commit.Tree != testTreeHash || len(commit.Parents) != 1 || commit.Parents[0] != testParentHash {
+		t.Errorf("got tree %s and parents %v", commit.Tree, commit.Parents)
+	}
+	if commit.Author.Name != "Kei-K23" || commit.Committer.Email != "other@example.com" || commit.Subject() != "Subject line" {
+		t.Errorf("got author %+v, committer %+v and subject '%s'", commit.Author, commit.Committer, commit.Subject())
+	}
+
+	encoded, err := EncodeCommit(commit)
+	if err != nil {
+		t.Fatalf("EncodeCommit failed: %v", err)
+	}
+	if string(encoded) != content {
+		t.Errorf("encoded commit differs from parsed content:\n%s", encoded)
+	}
+}
+
+func TestParseLegacyCommit(t *testing.T) {
+	// Commit written by older git-go versions with RFC3339 dates
+	content := "tree " + testTreeHash + "\n" +
+		"author author <author@gmail.com> 2024-09-24T15:54:09+06:30\n" +
+		"committer author <author@gmail.com> 2024-09-24T15:54:09+06:30\n\nfirst commit\n"
+
+	commit, err := ParseCommit([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseCommit failed: %v", err)
+	}
+	if commit.Author.Name != "author" || commit.Author.Email != "author@gmail.com" {
+		t.Errorf("got author %+v", commit.Author)
+	}
+	want := time.Date(2024, 9, 24, 9, 24, 9, 0, time.UTC)
+	if !commit.Committer.When.Equal(want) {
+		t.Errorf("got committer time %v, want %v", commit.Committer.When, want)
+	}
+	if commit.Subject() != "first commit" {
+		t.Errorf("got subject '%s'", commit.Subject())
+	}
+
+	// Legacy commit is written back in Git format
+	encoded, err := EncodeCommit(commit)
+	if err != nil {
+		t.Fatalf("EncodeCommit failed: %v", err)
+	}
+	if _, err := ParseCommit(encoded); err != nil {
+		t.Errorf("encoded legacy commit can't be parsed: %v", err)
+	}
+}
+
+func TestEncodeCommitErrors(t *testing.T) {
+	signature := Signature{Name: "Kei-K23", Email: "arkar.dev.kei@gmail.com", When: time.Unix(1727266964, 0)}
+	tests := map[string]Commit{
+		"bad tree":      {Tree: "tree", Author: signature, Committer: signature},
+		"bad parent":    {Tree: testTreeHash, Parents: []string{"HEAD"}, Author: signature, Committer: signature},
+		"bad signature": {Tree: testTreeHash, Author: Signature{Name: "a <b>", Email: "c"}, Committer: signature},
+	}
+	for name, commit := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := EncodeCommit(commit); err == nil {
+				t.Error("EncodeCommit succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestTreeRoundTrip(t *testing.T) {
+	entries := []TreeEntry{
+		{Mode: TreeMode, Name: "a", Hash: testTreeHash},
+		{Mode: "100644", Name: "a.txt", Hash: testParentHash},
+		{Mode: "120000", Name: "link", Hash: testParentHash},
+	}
+
+	content, err := EncodeTree(entries)
+	if err != nil {
+		t.Fatalf("EncodeTree failed: %v", err)
+	}
+	parsed, err := ParseTree(content)
+	if err != nil {
+		t.Fatalf("ParseTree failed: %v", err)
+	}
+
+	// Folder "a" is sorted as "a/", so it comes after "a.txt"
+	want := []TreeEntry{entries[1], entries[0], entries[2]}
+	if len(parsed) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(parsed), len(want))
+	}
+	for i := range want {
+		if parsed[i] != want[i] {
+			t.Errorf("entry %d is %+v, want %+v", i, parsed[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,498 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Single "key = value" line inside config section, comment and blank lines are kept as entries without key
+type ConfigEntry struct {
+	Key   string
+	Value string
+	raw   string // Original lines read from file, written back as they are until value changes
+}
+
+// Config section (e.g [user] or [remote "origin"]), section name and keys are case-insensitive but subsection is not
+type ConfigSection struct {
+	Name       string
+	Subsection string
+	Entries    []ConfigEntry
+	raw        string // Original header line read from file, it includes first entry when written on the same line
+	inlineKey  bool   // First entry is written on header line (e.g [user] name = foo)
+}
+
+// INI-style config file with the same syntax as Git config
+type Config struct {
+	Sections []*ConfigSection
+	preamble string // Comment and blank lines before the first section
+}
+
+// Returned by parseConfigValue when value continues on next line (line ends with backslash)
+var errConfigContinuation = errors.New("value continues on next line")
+
+// Split config key into section, subsection and key name (e.g remote.origin.url -> remote, origin, url)
+func SplitConfigKey(key string) (string, string, string, error) {
+	firstDot := strings.Index(key, ".")
+	lastDot := strings.LastIndex(key, ".")
+	if firstDot <= 0 || lastDot == len(key)-1 {
+		return "", "", "", fmt.Errorf("key does not contain a section: %s", key)
+	}
+
+	section := strings.ToLower(key[:firstDot])
+	name := strings.ToLower(key[lastDot+1:])
+	subsection := ""
+	if firstDot != lastDot {
+		subsection = key[firstDot+1 : lastDot]
+	}
+
+	return section, subsection, name, nil
+}
+
+// Parse config file content, original lines are kept so unchanged lines are written back as they are
+func ParseConfig(content []byte) (*Config, error) {
+	config := &Config{}
+	var current *ConfigSection
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		raw := scanner.Text() + "\n"
+		line := strings.TrimLeft(scanner.Text(), " \t")
+
+		// Keep empty lines and comments
+		if trimmed := strings.TrimSpace(line); trimmed == "" || trimmed[0] == '#' || trimmed[0] == ';' {
+			if current == nil {
+				config.preamble += raw
+			} else {
+				current.Entries = append(current.Entries, ConfigEntry{raw: raw})
+			}
+			continue
+		}
+
+		isHeaderLine := false
+		if line[0] == '[' {
+			closeIndex := strings.Index(line, "]")
+			if closeIndex == -1 {
+				return nil, fmt.Errorf("bad config line %d: missing ']'", lineNumber)
+			}
+
+			section, err := parseSectionHeader(line[1:closeIndex])
+			if err != nil {
+				return nil, fmt.Errorf("bad config line %d: %v", lineNumber, err)
+			}
+			section.raw = raw
+			config.Sections = append(config.Sections, section)
+			current = section
+
+			// Key can follow section header on the same line (e.g [user] name = foo)
+			line = strings.TrimLeft(line[closeIndex+1:], " \t")
+			if trimmed := strings.TrimSpace(line); trimmed == "" || trimmed[0] == '#' || trimmed[0] == ';' {
+				continue
+			}
+			isHeaderLine = true
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("bad config line %d: key outside of section", lineNumber)
+		}
+
+		key, rawValue, hasValue := strings.Cut(line, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			return nil, fmt.Errorf("bad config line %d: missing key", lineNumber)
+		}
+
+		// Key without value is boolean true (e.g [core] bare)
+		value := "true"
+		if hasValue {
+			var err error
+			value, err = parseConfigValue(rawValue)
+			// Backslash at end of line joins next line to the value
+			for err == errConfigContinuation && scanner.Scan() {
+				lineNumber++
+				raw += scanner.Text() + "\n"
+				rawValue += "\n" + scanner.Text()
+				value, err = parseConfigValue(rawValue)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("bad config line %d: %v", lineNumber, err)
+			}
+		}
+
+		entry := ConfigEntry{Key: key, Value: value, raw: raw}
+		if isHeaderLine {
+			// Line is already written with section header
+			current.raw, entry.raw = raw, ""
+			current.inlineKey = true
+		}
+		current.Entries = append(current.Entries, entry)
+	}
+
+	return config, scanner.Err()
+}
+
+// Parse section header without brackets (e.g core or remote "origin")
+func parseSectionHeader(header string) (*ConfigSection, error) {
+	header = strings.TrimSpace(header)
+
+	name, subsection, hasSubsection := strings.Cut(header, " ")
+	section := &ConfigSection{Name: strings.ToLower(name)}
+	if !hasSubsection {
+		// Old style subsection is written with dot (e.g [remote.origin])
+		if dotIndex := strings.Index(name, "."); dotIndex != -1 {
+			section.Name = strings.ToLower(name[:dotIndex])
+			section.Subsection = name[dotIndex+1:]
+		}
+		return section, nil
+	}
+
+	subsection = strings.TrimSpace(subsection)
+	if len(subsection) < 2 || subsection[0] != '"' || subsection[len(subsection)-1] != '"' {
+		return nil, fmt.Errorf("invalid subsection %s", subsection)
+	}
+	section.Subsection = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(subsection[1 : len(subsection)-1])
+
+	return section, nil
+}
+
+// Parse value part of config line, handling quotes, escapes and trailing comments
+// Value of several lines is joined with "\n", errConfigContinuation is returned when last line ends with backslash
+func parseConfigValue(rawValue string) (string, error) {
+	var sb strings.Builder
+	inQuote := false
+	pendingSpace := ""
+
+	rawValue = strings.TrimLeft(rawValue, " \t")
+	for i := 0; i < len(rawValue); i++ {
+		c := rawValue[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(rawValue) {
+				return "", errConfigContinuation
+			}
+			i++
+			if rawValue[i] == '\n' {
+				continue // Line continuation, backslash and new line are removed
+			}
+			sb.WriteString(pendingSpace)
+			pendingSpace = ""
+			switch rawValue[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\':
+				sb.WriteByte(rawValue[i])
+			default:
+				return "", fmt.Errorf("bad escape '\\%c'", rawValue[i])
+			}
+		case c == '"':
+			sb.WriteString(pendingSpace)
+			pendingSpace = ""
+			inQuote = !inQuote
+		case !inQuote && (c == '#' || c == ';'):
+			// Rest of line is comment
+			return sb.String(), nil
+		case !inQuote && (c == ' ' || c == '\t' || c == '\r'):
+			// Spaces between words are kept, trailing spaces are dropped
+			pendingSpace += string(c)
+		default:
+			sb.WriteString(pendingSpace)
+			pendingSpace = ""
+			sb.WriteByte(c)
+		}
+	}
+
+	if inQuote {
+		return "", fmt.Errorf("unterminated quote in value")
+	}
+
+	return sb.String(), nil
+}
+
+// Quote value when it can't be written as it is
+func formatConfigValue(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(value)
+	if escaped != value || strings.ContainsAny(value, "#;") || strings.TrimSpace(value) != value {
+		return `"` + escaped + `"`
+	}
+	return value
+}
+
+// Encode config to file content, lines read from file are written as they are unless their value changed
+func (config *Config) Encode() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(config.preamble)
+
+	for _, section := range config.Sections {
+		switch {
+		case section.raw != "":
+			buf.WriteString(section.raw)
+		case section.Subsection != "":
+			subsection := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(section.Subsection)
+			buf.WriteString(fmt.Sprintf("[%s \"%s\"]\n", section.Name, subsection))
+		default:
+			buf.WriteString(fmt.Sprintf("[%s]\n", section.Name))
+		}
+
+		for i, entry := range section.Entries {
+			switch {
+			case entry.raw != "":
+				buf.WriteString(entry.raw)
+			case i == 0 && section.inlineKey:
+				// Already written with section header
+			default:
+				buf.WriteString(fmt.Sprintf("\t%s = %s\n", entry.Key, formatConfigValue(entry.Value)))
+			}
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// Forget original line of changed (or removed) entry, header is formatted again when entry is written on its line
+func (section *ConfigSection) markChanged(index int) {
+	section.Entries[index].raw = ""
+	if index == 0 && section.inlineKey {
+		section.raw = ""
+		section.inlineKey = false
+	}
+}
+
+// Check section has any key or comment (blank lines don't count)
+func (section *ConfigSection) hasContent() bool {
+	for _, entry := range section.Entries {
+		if entry.Key != "" || strings.TrimSpace(entry.raw) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Find section with the given name and subsection
+func (config *Config) findSection(name string, subsection string) *ConfigSection {
+	for i := len(config.Sections) - 1; i >= 0; i-- {
+		section := config.Sections[i]
+		if section.Name == name && section.Subsection == subsection {
+			return section
+		}
+	}
+	return nil
+}
+
+// Get all values of the key in the order they appear (e.g multiple remote.origin.fetch lines)
+func (config *Config) GetAll(key string) []string {
+	sectionName, subsection, name, err := SplitConfigKey(key)
+	if err != nil {
+		return nil
+	}
+
+	var values []string
+	for _, section := range config.Sections {
+		if section.Name != sectionName || section.Subsection != subsection {
+			continue
+		}
+		for _, entry := range section.Entries {
+			if entry.Key == name {
+				values = append(values, entry.Value)
+			}
+		}
+	}
+	return values
+}
+
+// Get value of the key, the last value wins when key is given multiple times
+func (config *Config) Get(key string) (string, bool) {
+	values := config.GetAll(key)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
+
+// Set value of the key, the last existing value is replaced or new entry is added
+func (config *Config) Set(key string, value string) error {
+	sectionName, subsection, name, err := SplitConfigKey(key)
+	if err != nil {
+		return err
+	}
+
+	for i := len(config.Sections) - 1; i >= 0; i-- {
+		section := config.Sections[i]
+		if section.Name != sectionName || section.Subsection != subsection {
+			continue
+		}
+		for j := len(section.Entries) - 1; j >= 0; j-- {
+			if section.Entries[j].Key == name {
+				section.Entries[j].Value = value
+				section.markChanged(j)
+				return nil
+			}
+		}
+	}
+
+	return config.Add(key, value)
+}
+
+// Add new value of the key without replacing existing values
+func (config *Config) Add(key string, value string) error {
+	sectionName, subsection, name, err := SplitConfigKey(key)
+	if err != nil {
+		return err
+	}
+
+	section := config.findSection(sectionName, subsection)
+	if section == nil {
+		section = &ConfigSection{Name: sectionName, Subsection: subsection}
+		config.Sections = append(config.Sections, section)
+	}
+	// New key goes right after the last key of section, so blank lines and comments before next section stay there
+	index := 0
+	for i, entry := range section.Entries {
+		if entry.Key != "" {
+			index = i + 1
+		}
+	}
+	section.Entries = append(section.Entries[:index], append([]ConfigEntry{{Key: name, Value: value}}, section.Entries[index:]...)...)
+
+	return nil
+}
+
+// Remove every value of the key, sections that become empty are removed too (section with comments is kept)
+func (config *Config) Unset(key string) bool {
+	sectionName, subsection, name, err := SplitConfigKey(key)
+	if err != nil {
+		return false
+	}
+
+	removed := false
+	var sections []*ConfigSection
+	for _, section := range config.Sections {
+		if section.Name == sectionName && section.Subsection == subsection {
+			var entries []ConfigEntry
+			for i, entry := range section.Entries {
+				if entry.Key == name {
+					section.markChanged(i)
+					removed = true
+					continue
+				}
+				entries = append(entries, entry)
+			}
+			section.Entries = entries
+			if !section.hasContent() {
+				continue
+			}
+		}
+		sections = append(sections, section)
+	}
+	config.Sections = sections
+
+	return removed
+}
+
+// Remove whole section (e.g remote "origin")
+func (config *Config) RemoveSection(name string, subsection string) bool {
+	removed := false
+	var sections []*ConfigSection
+	for _, section := range config.Sections {
+		if section.Name == strings.ToLower(name) && section.Subsection == subsection {
+			removed = true
+			continue
+		}
+		sections = append(sections, section)
+	}
+	config.Sections = sections
+
+	return removed
+}
+
+// Get every key and value as "section.subsection.key" and value pairs in file order
+func (config *Config) List() []ConfigEntry {
+	var entries []ConfigEntry
+	for _, section := range config.Sections {
+		prefix := section.Name + "."
+		if section.Subsection != "" {
+			prefix += section.Subsection + "."
+		}
+		for _, entry := range section.Entries {
+			if entry.Key != "" {
+				entries = append(entries, ConfigEntry{Key: prefix + entry.Key, Value: entry.Value})
+			}
+		}
+	}
+	return entries
+}
+
+// Read and parse config file, missing file is same as empty config
+func LoadConfigFile(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, err
+	}
+
+	return ParseConfig(content)
+}
+
+// Write config to file
+func (config *Config) Save(path string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, config.Encode(), 0644)
+}
+
+// Get path of repository level config file
+func RepoConfigPath() string {
+	return GitPath("config")
+}
+
+// Get path of user level config file (GIT_GO_CONFIG_GLOBAL or ~/.gitgoconfig)
+func GlobalConfigPath() string {
+	if path := os.Getenv("GIT_GO_CONFIG_GLOBAL"); path != "" {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".gitgoconfig"
+	}
+	return filepath.Join(homeDir, ".gitgoconfig")
+}
+
+// Load user level and repository level config merged together, repository values win over user values
+func LoadConfig() (*Config, error) {
+	globalConfig, err := LoadConfigFile(GlobalConfigPath())
+	if err != nil {
+		return nil, err
+	}
+
+	repoConfig, err := LoadConfigFile(RepoConfigPath())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{Sections: append(globalConfig.Sections, repoConfig.Sections...)}, nil
+}
+
+// Get config value from merged user and repository config
+func GetConfigValue(key string) (string, bool) {
+	config, err := LoadConfig()
+	if err != nil {
+		return "", false
+	}
+	return config.Get(key)
+}
@@ -0,0 +1,215 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Size of base blocks that are indexed to find copy candidates
+const deltaBlockSize = 16
+
+// Longest data that can be added by one insert instruction
+const deltaMaxInsert = 0x7f
+
+// Longest data that can be copied by one copy instruction (3 size bytes)
+const deltaMaxCopy = 0xffffff
+
+// Index of base object blocks, it is built once and reused to create deltas of many targets
+type DeltaIndex struct {
+	base   []byte
+	blocks map[string]int // Content of block to its offset in base
+}
+
+// Build index of base object content for creating deltas against it
+func NewDeltaIndex(base []byte) *DeltaIndex {
+	index := &DeltaIndex{base: base, blocks: make(map[string]int)}
+	for offset := 0; offset+deltaBlockSize <= len(base); offset += deltaBlockSize {
+		block := string(base[offset : offset+deltaBlockSize])
+		if _, ok := index.blocks[block]; !ok {
+			index.blocks[block] = offset
+		}
+	}
+	return index
+}
+
+// Encode size in delta header (7 bits per byte, least significant group first)
+func appendDeltaSize(delta []byte, size int) []byte {
+	for size >= 0x80 {
+		delta = append(delta, byte(size&0x7f)|0x80)
+		size >>= 7
+	}
+	return append(delta, byte(size))
+}
+
+// Decode size from delta header, the number of used bytes is returned too
+func readDeltaSize(delta []byte) (int, int, error) {
+	size, shift := 0, 0
+	for i, b := range delta {
+		if shift > 56 {
+			break
+		}
+		size |= int(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			return size, i + 1, nil
+		}
+	}
+	return 0, 0, errors.New("delta header is truncated")
+}
+
+// Append insert instructions for literal data
+func appendDeltaInsert(delta []byte, data []byte) []byte {
+	for len(data) > 0 {
+		n := min(len(data), deltaMaxInsert)
+		delta = append(delta, byte(n))
+		delta = append(delta, data[:n]...)
+		data = data[n:]
+	}
+	return delta
+}
+
+// Append copy instructions, only non-zero offset and size bytes are written and marked in instruction byte
+func appendDeltaCopy(delta []byte, offset int, size int) []byte {
+	for size > 0 {
+		n := min(size, deltaMaxCopy)
+
+		instruction := byte(0x80)
+		var args []byte
+		for i := 0; i < 4; i++ {
+			if b := byte(offset >> (8 * i)); b != 0 {
+				instruction |= 1 << i
+				args = append(args, b)
+			}
+		}
+		for i := 0; i < 3; i++ {
+			if b := byte(n >> (8 * i)); b != 0 {
+				instruction |= 1 << (4 + i)
+				args = append(args, b)
+			}
+		}
+		delta = append(delta, instruction)
+		delta = append(delta, args...)
+
+		offset += n
+		size -= n
+	}
+	return delta
+}
+
+// Create delta that rebuilds target from base content of the index
+// Delta is made of copy instructions for data found in base and insert instructions for new data
+func (index *DeltaIndex) CreateDelta(target []byte) []byte {
+	delta := appendDeltaSize(nil, len(index.base))
+	delta = appendDeltaSize(delta, len(target))
+
+	insertStart := 0 // Start of target data that is not covered by copy instruction yet
+	position := 0
+	for position+deltaBlockSize <= len(target) {
+		baseOffset, ok := index.blocks[string(target[position:position+deltaBlockSize])]
+		if !ok {
+			position++
+			continue
+		}
+
+		// Extend match backward into pending insert data, then forward as far as possible
+		matchStart, baseStart := position, baseOffset
+		for matchStart > insertStart && baseStart > 0 && target[matchStart-1] == index.base[baseStart-1] {
+			matchStart--
+			baseStart--
+		}
+		matchEnd := position + deltaBlockSize
+		for matchEnd < len(target) && baseOffset+(matchEnd-position) < len(index.base) && target[matchEnd] == index.base[baseOffset+(matchEnd-position)] {
+			matchEnd++
+		}
+
+		delta = appendDeltaInsert(delta, target[insertStart:matchStart])
+		delta = appendDeltaCopy(delta, baseStart, matchEnd-matchStart)
+
+		insertStart = matchEnd
+		position = matchEnd
+	}
+	delta = appendDeltaInsert(delta, target[insertStart:])
+
+	return delta
+}
+
+// Rebuild target content by applying delta instructions to base content
+func ApplyDelta(base []byte, delta []byte) ([]byte, error) {
+	baseSize, n, err := readDeltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+	delta = delta[n:]
+	if baseSize != len(base) {
+		return nil, fmt.Errorf("delta base size mismatch (expected %d, actual %d)", baseSize, len(base))
+	}
+
+	targetSize, n, err := readDeltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+	delta = delta[n:]
+
+	// Target size is not trusted for allocation, delta of a received pack may claim any size
+	var target bytes.Buffer
+	target.Grow(min(targetSize, len(base)+len(delta)))
+
+	for len(delta) > 0 {
+		instruction := delta[0]
+		delta = delta[1:]
+
+		if instruction&0x80 == 0 {
+			// Insert instruction, lower 7 bits are length of literal data
+			if instruction == 0 {
+				return nil, errors.New("delta has reserved instruction")
+			}
+			length := int(instruction)
+			if length > len(delta) {
+				return nil, errors.New("delta insert instruction is truncated")
+			}
+			if target.Len()+length > targetSize {
+				return nil, errors.New("delta target is longer than its size")
+			}
+			target.Write(delta[:length])
+			delta = delta[length:]
+			continue
+		}
+
+		// Copy instruction, bits tell which offset and size bytes follow
+		offset, size := 0, 0
+		for i := 0; i < 7; i++ {
+			if instruction&(1<<i) == 0 {
+				continue
+			}
+			if len(delta) == 0 {
+				return nil, errors.New("delta copy instruction is truncated")
+			}
+			if i < 4 {
+				offset |= int(delta[0]) << (8 * i)
+			} else {
+				size |= int(delta[0]) << (8 * (i - 4))
+			}
+			delta = delta[1:]
+		}
+		if size == 0 {
+			size = 0x10000
+		}
+		if offset+size > len(base) {
+			return nil, errors.New("delta copy instruction is out of base range")
+		}
+		if target.Len()+size > targetSize {
+			return nil, errors.New("delta target is longer than its size")
+		}
+		target.Write(base[offset : offset+size])
+	}
+
+	if target.Len() != targetSize {
+		return nil, fmt.Errorf("delta target size mismatch (expected %d, actual %d)", targetSize, target.Len())
+	}
+
+	return target.Bytes(), nil
+}
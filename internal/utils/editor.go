@@ -0,0 +1,60 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Get editor command, GIT_GO_EDITOR and core.editor come before VISUAL and EDITOR (vi is used when nothing is set)
+func EditorCommand() string {
+	if editor := os.Getenv("GIT_GO_EDITOR"); editor != "" {
+		return editor
+	}
+	if editor, ok := GetConfigValue("core.editor"); ok && editor != "" {
+		return editor
+	}
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(name); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// Open file in editor and wait until editor is closed, editor command can have arguments (e.g "code --wait")
+func EditFile(path string) error {
+	editor := EditorCommand()
+
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("there was a problem with the editor '%s': %v", editor, err)
+	}
+	return nil
+}
+
+// Remove comment lines ("#"), trailing spaces and surrounding blank lines from edited text
+// Consecutive blank lines are collapsed into one (same as default cleanup of Git)
+func CleanupMessage(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" && len(lines) > 0 && lines[len(lines)-1] == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
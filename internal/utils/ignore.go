@@ -0,0 +1,170 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Name of file with ignore patterns, it can be placed in any folder of working tree
+const IgnoreFileName = ".gitignore"
+
+// One pattern line of .gitignore or info/exclude
+type ignorePattern struct {
+	base     string // Folder of .gitignore file relative to working tree root (empty for root and info/exclude)
+	pattern  string
+	negate   bool // "!" prefix, matching path is included again
+	dirOnly  bool // "/" suffix, only folders match
+	anchored bool // Pattern has "/" (not at the end), so it matches path relative to base instead of only the name
+}
+
+// Check path (relative to working tree root) matches the pattern
+func (p ignorePattern) matches(filePath string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+
+	relPath := filePath
+	if p.base != "" {
+		if !strings.HasPrefix(filePath, p.base+"/") {
+			return false
+		}
+		relPath = filePath[len(p.base)+1:]
+	}
+
+	if p.anchored {
+		return matchGlobPath(p.pattern, relPath)
+	}
+	return matchGlobSegment(p.pattern, path.Base(relPath))
+}
+
+// Parse content of ignore file, blank lines and comments are skipped
+func parseIgnorePatterns(content string, base string) []ignorePattern {
+	var patterns []ignorePattern
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		// Trailing spaces are ignored unless they are escaped with backslash
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+			line = line[:len(line)-1]
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p := ignorePattern{base: base}
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		p.pattern = line
+		patterns = append(patterns, p)
+	}
+
+	return patterns
+}
+
+// Match path against glob pattern by folder, "*", "?" and "[...]" don't match "/" and "**" matches any number of folders
+func matchGlobPath(pattern string, filePath string) bool {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(filePath, "/"))
+}
+
+func matchGlobSegments(patterns []string, parts []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			rest := patterns[1:]
+			// Trailing "**" matches everything inside folder
+			if len(rest) == 0 {
+				return len(parts) > 0
+			}
+			for i := 0; i <= len(parts); i++ {
+				if matchGlobSegments(rest, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(parts) == 0 || !matchGlobSegment(patterns[0], parts[0]) {
+			return false
+		}
+		patterns, parts = patterns[1:], parts[1:]
+	}
+
+	return len(parts) == 0
+}
+
+// Match one file or folder name against glob pattern ("[!...]" is same as "[^...]")
+func matchGlobSegment(pattern string, name string) bool {
+	matched, err := path.Match(strings.ReplaceAll(pattern, "[!", "[^"), name)
+	return err == nil && matched
+}
+
+// Matcher of ignore patterns from info/exclude and .gitignore files, .gitignore files are read when their folder is first checked
+type IgnoreMatcher struct {
+	patterns []ignorePattern
+	loaded   map[string]bool // Folders whose .gitignore file is already read
+}
+
+// Create ignore matcher for working tree, patterns of info/exclude have lower priority than .gitignore files
+func NewIgnoreMatcher() *IgnoreMatcher {
+	matcher := &IgnoreMatcher{loaded: make(map[string]bool)}
+	if content, err := os.ReadFile(GitPath("info", "exclude")); err == nil {
+		matcher.patterns = parseIgnorePatterns(string(content), "")
+	}
+	return matcher
+}
+
+// Read .gitignore file of folder once, patterns of deeper folders come later so they win over parent folders
+func (m *IgnoreMatcher) loadDir(dir string) {
+	if m.loaded[dir] {
+		return
+	}
+	m.loaded[dir] = true
+
+	content, err := os.ReadFile(filepath.Join(filepath.FromSlash(dir), IgnoreFileName))
+	if err != nil {
+		return
+	}
+	m.patterns = append(m.patterns, parseIgnorePatterns(string(content), dir)...)
+}
+
+// Check path (relative to working tree root) is ignored, the last matching pattern decides
+// Everything inside ignored folder is ignored too and can't be included again by negation (same as Git)
+func (m *IgnoreMatcher) IsIgnored(filePath string, isDir bool) bool {
+	parts := strings.Split(filePath, "/")
+	for i := 1; i <= len(parts); i++ {
+		m.loadDir(strings.Join(parts[:i-1], "/"))
+
+		current := strings.Join(parts[:i], "/")
+		isCurrentDir := i < len(parts) || isDir
+
+		ignored := false
+		for _, p := range m.patterns {
+			if p.matches(current, isCurrentDir) {
+				ignored = !p.negate
+			}
+		}
+		if ignored {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,352 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Index file format modeled on Git's DIRC version 2
+const (
+	indexSignature = "DIRC"
+	indexVersion   = 2
+	// Size of fixed part of each entry (stat data, hash value and flags)
+	indexEntryFixedSize = 62
+	// Maximum name length that can be stored in entry flags
+	indexNameMask = 0xFFF
+)
+
+type IndexEntry struct {
+	Mode string
+	Hash string
+	Path string
+
+	// Cached stat data of working tree file to detect changes without hashing file content
+	CTimeSeconds     uint32
+	CTimeNanoseconds uint32
+	MTimeSeconds     uint32
+	MTimeNanoseconds uint32
+	Dev              uint32
+	Ino              uint32
+	UID              uint32
+	GID              uint32
+	Size             uint32
+	Flags            uint16
+}
+
+// Check both entries point to the same content with the same mode (stat data is ignored)
+func (entry IndexEntry) SameContent(other IndexEntry) bool {
+	return entry.Mode == other.Mode && entry.Hash == other.Hash
+}
+
+// Get merge stage of entry (0 for normal entry, 1 base, 2 ours and 3 theirs for conflicted entry)
+func (entry IndexEntry) Stage() int {
+	return int(entry.Flags>>12) & 3
+}
+
+// Set merge stage of entry
+func (entry *IndexEntry) SetStage(stage int) {
+	entry.Flags = entry.Flags&^(3<<12) | uint16(stage&3)<<12
+}
+
+// Get paths that have unresolved merge conflict (entries with stage other than 0)
+func UnmergedPaths(entries []IndexEntry) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Stage() != 0 && !seen[entry.Path] {
+			seen[entry.Path] = true
+			paths = append(paths, entry.Path)
+		}
+	}
+	return paths
+}
+
+// Modification time of index file when it was last read or written, used to detect racily clean entries
+var indexModTime time.Time
+
+func ReadIndexFile() ([]IndexEntry, error) {
+	// Read the whole index file
+	content, err := os.ReadFile(GitPath("index"))
+	if err != nil {
+		// If the file doesn't exist, return an empty list of entries (new repository)
+		if os.IsNotExist(err) {
+			return []IndexEntry{}, nil
+		}
+		return nil, err
+	}
+
+	// If the file size is zero, return an empty slice
+	if len(content) == 0 {
+		return []IndexEntry{}, nil
+	}
+
+	if fileInfo, err := os.Stat(GitPath("index")); err == nil {
+		indexModTime = fileInfo.ModTime()
+	}
+
+	// Index file written by older version of git-go is zlib compressed text
+	if !bytes.HasPrefix(content, []byte(indexSignature)) {
+		return decodeTextIndex(content)
+	}
+
+	return DecodeIndex(content)
+}
+
+func WriteIndexFile(entries []IndexEntry) error {
+	// Index entries are always stored sorted by path
+	sorted := make([]IndexEntry, len(entries))
+	copy(sorted, entries)
+	SortEntries(sorted)
+
+	content, err := EncodeIndex(sorted)
+	if err != nil {
+		return err
+	}
+
+	// Write to temporary file first, so index is never left half written
+	err = os.WriteFile(GitPath("index.lock"), content, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(GitPath("index.lock"), GitPath("index"))
+	if err != nil {
+		return err
+	}
+
+	if fileInfo, err := os.Stat(GitPath("index")); err == nil {
+		indexModTime = fileInfo.ModTime()
+	}
+
+	return nil
+}
+
+// Encode index entries to binary index content with header and trailing checksum
+func EncodeIndex(entries []IndexEntry) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString(indexSignature)
+	binary.Write(&buf, binary.BigEndian, uint32(indexVersion))
+	binary.Write(&buf, binary.BigEndian, uint32(len(entries)))
+
+	for _, entry := range entries {
+		mode, err := strconv.ParseUint(entry.Mode, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mode '%s' for index entry '%s'", entry.Mode, entry.Path)
+		}
+
+		rawHash, err := hex.DecodeString(entry.Hash)
+		if err != nil || len(rawHash) != 20 {
+			return nil, fmt.Errorf("invalid hash value '%s' for index entry '%s'", entry.Hash, entry.Path)
+		}
+
+		nameLength := len(entry.Path)
+		if nameLength > indexNameMask {
+			nameLength = indexNameMask
+		}
+		// Only keep high bits (assume-valid and stage) of flags, low bits store name length
+		flags := entry.Flags&^indexNameMask | uint16(nameLength)
+
+		for _, value := range []uint32{
+			entry.CTimeSeconds, entry.CTimeNanoseconds,
+			entry.MTimeSeconds, entry.MTimeNanoseconds,
+			entry.Dev, entry.Ino, uint32(mode),
+			entry.UID, entry.GID, entry.Size,
+		} {
+			binary.Write(&buf, binary.BigEndian, value)
+		}
+		buf.Write(rawHash)
+		binary.Write(&buf, binary.BigEndian, flags)
+		buf.WriteString(entry.Path)
+
+		// Entry is padded with 1 to 8 NUL bytes so entry size is multiple of 8
+		entrySize := indexEntryFixedSize + len(entry.Path)
+		padding := 8 - entrySize%8
+		buf.Write(make([]byte, padding))
+	}
+
+	checksum := sha1.Sum(buf.Bytes())
+	buf.Write(checksum[:])
+
+	return buf.Bytes(), nil
+}
+
+// Decode binary index content and verify its checksum
+func DecodeIndex(content []byte) ([]IndexEntry, error) {
+	if len(content) < 12+sha1.Size {
+		return nil, errors.New("index file is too short")
+	}
+
+	body := content[:len(content)-sha1.Size]
+	checksum := sha1.Sum(body)
+	if !bytes.Equal(checksum[:], content[len(content)-sha1.Size:]) {
+		return nil, errors.New("index file checksum mismatch")
+	}
+
+	version := binary.BigEndian.Uint32(body[4:8])
+	if version != indexVersion {
+		return nil, fmt.Errorf("unsupported index version %d", version)
+	}
+	count := binary.BigEndian.Uint32(body[8:12])
+
+	entries := make([]IndexEntry, 0, count)
+	offset := 12
+	for i := uint32(0); i < count; i++ {
+		if offset+indexEntryFixedSize > len(body) {
+			return nil, errors.New("truncated index entry")
+		}
+		data := body[offset:]
+
+		values := make([]uint32, 10)
+		for j := range values {
+			values[j] = binary.BigEndian.Uint32(data[j*4 : j*4+4])
+		}
+		hashValue := hex.EncodeToString(data[40:60])
+		flags := binary.BigEndian.Uint16(data[60:62])
+
+		// Path is terminated by NUL byte (name length in flags is capped for long path)
+		nameEnd := bytes.IndexByte(data[indexEntryFixedSize:], 0)
+		if nameEnd == -1 {
+			return nil, errors.New("unterminated path in index entry")
+		}
+		path := string(data[indexEntryFixedSize : indexEntryFixedSize+nameEnd])
+
+		entries = append(entries, IndexEntry{
+			Mode:             strconv.FormatUint(uint64(values[6]), 8),
+			Hash:             hashValue,
+			Path:             path,
+			CTimeSeconds:     values[0],
+			CTimeNanoseconds: values[1],
+			MTimeSeconds:     values[2],
+			MTimeNanoseconds: values[3],
+			Dev:              values[4],
+			Ino:              values[5],
+			UID:              values[7],
+			GID:              values[8],
+			Size:             values[9],
+			Flags:            flags,
+		})
+
+		entrySize := indexEntryFixedSize + len(path)
+		offset += entrySize + (8 - entrySize%8)
+	}
+
+	return entries, nil
+}
+
+// Decode index written as zlib compressed "mode hash path" lines by older version of git-go
+func decodeTextIndex(content []byte) ([]IndexEntry, error) {
+	decompressedContent, err := DecompressContent(bytes.NewBuffer(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []IndexEntry
+	for _, line := range bytes.Split(decompressedContent, []byte("\n")) {
+		parts := bytes.Fields(line) // Split by whitespace
+		if len(parts) != 3 {
+			continue // Ensure the line has 3 parts (mode, hash, path)
+		}
+
+		entries = append(entries, IndexEntry{
+			Mode: string(parts[0]),
+			Hash: string(parts[1]),
+			Path: string(parts[2]),
+		})
+	}
+
+	return entries, nil
+}
+
+// Add new entry to index entries or replace existing entries with the same path (conflict stages are resolved too)
+func UpdateIndexEntry(entries []IndexEntry, newEntry IndexEntry) []IndexEntry {
+	var updatedEntries []IndexEntry
+	for _, entry := range entries {
+		if entry.Path != newEntry.Path {
+			updatedEntries = append(updatedEntries, entry)
+		}
+	}
+
+	// If index entry is new, them add to entry array slice
+	updatedEntries = append(updatedEntries, newEntry)
+	SortEntries(updatedEntries)
+
+	return updatedEntries
+}
+
+// Get index entry mode from file info (Git only records executable bit for regular files)
+func FileModeOf(fileInfo os.FileInfo) string {
+	if fileInfo.Mode()&os.ModeSymlink != 0 {
+		return "120000"
+	}
+	if fileInfo.Mode().Perm()&0111 != 0 {
+		return "100755"
+	}
+	return "100644"
+}
+
+// Fill cached stat data of index entry from file info
+func SetEntryStat(entry *IndexEntry, fileInfo os.FileInfo) {
+	modTime := fileInfo.ModTime()
+	entry.MTimeSeconds = uint32(modTime.Unix())
+	entry.MTimeNanoseconds = uint32(modTime.Nanosecond())
+	entry.Size = uint32(fileInfo.Size())
+
+	// ctime, device, inode and owner are only available from system specific stat data
+	fillSystemStat(entry, fileInfo)
+}
+
+// Create index entry for working tree file with its stat data
+func NewIndexEntry(path string, hashValue string) (IndexEntry, error) {
+	fileInfo, err := os.Lstat(path)
+	if err != nil {
+		return IndexEntry{}, err
+	}
+
+	entry := IndexEntry{
+		Mode: FileModeOf(fileInfo),
+		Hash: hashValue,
+		Path: path,
+	}
+	SetEntryStat(&entry, fileInfo)
+
+	return entry, nil
+}
+
+// Update cached stat data of entry from current working tree file, entry is returned as it is when file is missing
+func RefreshEntryStat(entry IndexEntry) IndexEntry {
+	fileInfo, err := os.Lstat(entry.Path)
+	if err != nil {
+		return entry
+	}
+
+	SetEntryStat(&entry, fileInfo)
+	return entry
+}
+
+// Check stat data of working tree file is same as cached stat data, so file content doesn't need to be hashed again
+func IsEntryStatClean(entry IndexEntry, fileInfo os.FileInfo) bool {
+	var current IndexEntry
+	SetEntryStat(&current, fileInfo)
+
+	if current.MTimeSeconds != entry.MTimeSeconds || current.MTimeNanoseconds != entry.MTimeNanoseconds ||
+		current.CTimeSeconds != entry.CTimeSeconds || current.CTimeNanoseconds != entry.CTimeNanoseconds ||
+		current.Size != entry.Size || current.Ino != entry.Ino || current.Dev != entry.Dev ||
+		FileModeOf(fileInfo) != entry.Mode {
+		return false
+	}
+
+	// File modified in the same moment as index was written can change again without changing stat data (racy Git)
+	entryModTime := time.Unix(int64(entry.MTimeSeconds), int64(entry.MTimeNanoseconds))
+	return entryModTime.Before(indexModTime)
+}
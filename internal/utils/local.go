@@ -0,0 +1,111 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Find repository folder of local repository (working tree with .git-go folder or the repository folder itself)
+func FindLocalRepository(path string) (string, error) {
+	candidates := []string{filepath.Join(path, GitDirName), path}
+	for _, candidate := range candidates {
+		if isRepositoryFolder(candidate) {
+			return filepath.Abs(candidate)
+		}
+	}
+	return "", fmt.Errorf("repository '%s' does not exist", path)
+}
+
+// Check folder has HEAD file, objects and refs folders like every repository folder
+func isRepositoryFolder(dir string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Read every reference of another repository by full name (e.g refs/heads/main) together with its HEAD content
+func ReadLocalRefs(gitDir string) (map[string]string, string, error) {
+	headContent, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return nil, "", err
+	}
+
+	refs := make(map[string]string)
+	for _, name := range listRefFiles(filepath.Join(gitDir, "refs")) {
+		content, err := os.ReadFile(filepath.Join(gitDir, "refs", filepath.FromSlash(name)))
+		if err != nil {
+			return nil, "", err
+		}
+		refs["refs/"+name] = strings.TrimSpace(string(content))
+	}
+
+	return refs, strings.TrimSpace(string(headContent)), nil
+}
+
+// Copy objects folder (loose objects and packs) of another repository, files are hard linked when possible
+func CopyObjects(srcGitDir string, useHardlinks bool) error {
+	srcObjects := filepath.Join(srcGitDir, "objects")
+
+	return filepath.WalkDir(srcObjects, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(srcObjects, path)
+		if err != nil {
+			return err
+		}
+		dstPath := GitPath("objects", relPath)
+
+		if d.IsDir() {
+			return os.MkdirAll(dstPath, 0755)
+		}
+
+		// Temporary file of unfinished write is not an object
+		if strings.HasPrefix(d.Name(), "tmp_") || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		if _, err := os.Stat(dstPath); err == nil {
+			return nil
+		}
+
+		if useHardlinks && os.Link(path, dstPath) == nil {
+			return nil
+		}
+		return copyFile(path, dstPath)
+	})
+}
+
+// Copy file content with the same permission bits
+func copyFile(srcPath string, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,299 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Kei-K23/git-go/internal/diff"
+)
+
+// Result of three-way merge of two snapshots against their common ancestor
+type MergeResult struct {
+	Entries         []IndexEntry      // Merged entries, conflicted path has stage 1 (base), 2 (ours) and 3 (theirs) entries
+	Conflicts       []string          // Paths with unresolved conflict
+	WorkingContents map[string][]byte // Working tree content of conflicted paths (with conflict markers when possible)
+}
+
+// Check both sides have the same file (or both don't have it)
+func sameEntry(first IndexEntry, inFirst bool, second IndexEntry, inSecond bool) bool {
+	return inFirst == inSecond && (!inFirst || first.SameContent(second))
+}
+
+// Read blob content of entry, missing entry is empty content
+func entryContent(entry IndexEntry, exists bool) ([]byte, error) {
+	if !exists {
+		return []byte{}, nil
+	}
+	return ReadBlobObject(entry.Hash)
+}
+
+// Merge ours and theirs snapshots with base snapshot as common ancestor
+// Path changed only in one side takes that change, path changed in both sides is merged line by line
+func MergeTrees(baseEntries, oursEntries, theirsEntries []IndexEntry, oursLabel, theirsLabel string) (MergeResult, error) {
+	result := MergeResult{WorkingContents: make(map[string][]byte)}
+
+	baseFiles := EntriesToMap(baseEntries)
+	oursFiles := EntriesToMap(oursEntries)
+	theirsFiles := EntriesToMap(theirsEntries)
+
+	pathSet := make(map[string]bool)
+	for _, files := range []map[string]IndexEntry{baseFiles, oursFiles, theirsFiles} {
+		for path := range files {
+			pathSet[path] = true
+		}
+	}
+	var paths []string
+	for path := range pathSet {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		baseEntry, inBase := baseFiles[path]
+		oursEntry, inOurs := oursFiles[path]
+		theirsEntry, inTheirs := theirsFiles[path]
+
+		switch {
+		case sameEntry(oursEntry, inOurs, theirsEntry, inTheirs):
+			// Both sides are same
+			if inOurs {
+				result.Entries = append(result.Entries, oursEntry)
+			}
+			continue
+		case sameEntry(baseEntry, inBase, oursEntry, inOurs):
+			// Only theirs changed the file
+			if inTheirs {
+				result.Entries = append(result.Entries, theirsEntry)
+			}
+			continue
+		case sameEntry(baseEntry, inBase, theirsEntry, inTheirs):
+			// Only ours changed the file
+			if inOurs {
+				result.Entries = append(result.Entries, oursEntry)
+			}
+			continue
+		}
+
+		baseContent, err := entryContent(baseEntry, inBase)
+		if err != nil {
+			return MergeResult{}, err
+		}
+		oursContent, err := entryContent(oursEntry, inOurs)
+		if err != nil {
+			return MergeResult{}, err
+		}
+		theirsContent, err := entryContent(theirsEntry, inTheirs)
+		if err != nil {
+			return MergeResult{}, err
+		}
+
+		// Both sides changed the file, try to merge content line by line
+		if inOurs && inTheirs {
+			mode, modeClean := mergeMode(baseEntry, inBase, oursEntry, theirsEntry)
+			isBinary := diff.IsBinary(baseContent) || diff.IsBinary(oursContent) || diff.IsBinary(theirsContent)
+
+			if !isBinary {
+				merged, clean := diff.Merge3(baseContent, oursContent, theirsContent, oursLabel, theirsLabel)
+				if clean && modeClean {
+					hashValue, err := WriteObject(BlobObject, merged)
+					if err != nil {
+						return MergeResult{}, err
+					}
+					result.Entries = append(result.Entries, IndexEntry{Mode: mode, Hash: hashValue, Path: path})
+					continue
+				}
+				result.WorkingContents[path] = merged
+			} else {
+				// Binary file can't be merged, keep our version in working tree
+				result.WorkingContents[path] = oursContent
+			}
+		} else if inOurs {
+			// Modified in ours but deleted in theirs
+			result.WorkingContents[path] = oursContent
+		} else {
+			// Deleted in ours but modified in theirs
+			result.WorkingContents[path] = theirsContent
+		}
+
+		// Record every version of conflicted file as merge stage entries
+		result.Conflicts = append(result.Conflicts, path)
+		for stage, side := range []struct {
+			entry  IndexEntry
+			exists bool
+		}{{baseEntry, inBase}, {oursEntry, inOurs}, {theirsEntry, inTheirs}} {
+			if !side.exists {
+				continue
+			}
+			stageEntry := IndexEntry{Mode: side.entry.Mode, Hash: side.entry.Hash, Path: path}
+			stageEntry.SetStage(stage + 1)
+			result.Entries = append(result.Entries, stageEntry)
+		}
+	}
+
+	SortEntries(result.Entries)
+
+	return result, nil
+}
+
+// Decide file mode when both sides changed the file, false is returned when both sides changed mode differently
+func mergeMode(baseEntry IndexEntry, inBase bool, oursEntry IndexEntry, theirsEntry IndexEntry) (string, bool) {
+	switch {
+	case oursEntry.Mode == theirsEntry.Mode:
+		return oursEntry.Mode, true
+	case inBase && baseEntry.Mode == oursEntry.Mode:
+		return theirsEntry.Mode, true
+	case inBase && baseEntry.Mode == theirsEntry.Mode:
+		return oursEntry.Mode, true
+	}
+	return oursEntry.Mode, false
+}
+
+// Get tracked paths with staged or unstaged changes compared to HEAD commit
+func LocalChanges() ([]string, error) {
+	headFiles := EntriesToMap(GetCommitFiles(GetCurrentCommit()))
+
+	indexEntries, err := ReadIndexFile()
+	if err != nil {
+		return nil, err
+	}
+
+	changedSet := make(map[string]bool)
+	indexFiles := make(map[string]IndexEntry)
+	for _, entry := range indexEntries {
+		indexFiles[entry.Path] = entry
+		if entry.Stage() != 0 {
+			changedSet[entry.Path] = true
+			continue
+		}
+
+		// Staged change
+		headEntry, inHead := headFiles[entry.Path]
+		if !inHead || !headEntry.SameContent(entry) {
+			changedSet[entry.Path] = true
+			continue
+		}
+
+		// Unstaged change, stat data is checked first to avoid hashing unchanged file
+		fileInfo, err := os.Lstat(entry.Path)
+		if err != nil {
+			changedSet[entry.Path] = true
+			continue
+		}
+		if IsEntryStatClean(entry, fileInfo) {
+			continue
+		}
+		workingHash, err := HashWorkingFile(entry.Path)
+		if err != nil || workingHash != entry.Hash || FileModeOf(fileInfo) != entry.Mode {
+			changedSet[entry.Path] = true
+		}
+	}
+
+	// Staged deletion
+	for path := range headFiles {
+		if _, ok := indexFiles[path]; !ok {
+			changedSet[path] = true
+		}
+	}
+
+	var changed []string
+	for path := range changedSet {
+		changed = append(changed, path)
+	}
+	sort.Strings(changed)
+
+	return changed, nil
+}
+
+// Update working tree and index from current index snapshot to merge result
+// Files changed by merge must not have unstaged changes, untracked files that would be overwritten make it fail
+func ApplyMergeResult(result MergeResult) error {
+	indexEntries, err := ReadIndexFile()
+	if err != nil {
+		return err
+	}
+	indexFiles := EntriesToMap(indexEntries)
+
+	resultFiles := make(map[string]IndexEntry)
+	for _, entry := range result.Entries {
+		if entry.Stage() == 0 {
+			resultFiles[entry.Path] = entry
+		}
+	}
+
+	// Make sure untracked files are never overwritten
+	var untrackedFiles []string
+	for _, entry := range result.Entries {
+		if _, tracked := indexFiles[entry.Path]; tracked {
+			continue
+		}
+		if len(untrackedFiles) > 0 && untrackedFiles[len(untrackedFiles)-1] == entry.Path {
+			continue
+		}
+		if _, err := os.Lstat(entry.Path); err != nil {
+			continue
+		}
+		// Untracked file with exactly the merged content is safe to keep
+		if workingHash, err := HashWorkingFile(entry.Path); err == nil && entry.Stage() == 0 && workingHash == entry.Hash {
+			continue
+		}
+		untrackedFiles = append(untrackedFiles, entry.Path)
+	}
+	if len(untrackedFiles) > 0 {
+		return errors.New("The following untracked working tree files would be overwritten by merge:\n\t" +
+			strings.Join(untrackedFiles, "\n\t") + "\nPlease move or remove them before you merge.")
+	}
+
+	var newEntries []IndexEntry
+	for _, entry := range result.Entries {
+		if entry.Stage() != 0 {
+			newEntries = append(newEntries, entry)
+			continue
+		}
+
+		if indexEntry, ok := indexFiles[entry.Path]; ok && indexEntry.SameContent(entry) {
+			// File is not changed by merge, keep cached stat data
+			newEntries = append(newEntries, indexEntry)
+			continue
+		}
+
+		err = WriteWorkingFile(entry)
+		if err != nil {
+			return err
+		}
+		newEntries = append(newEntries, RefreshEntryStat(entry))
+	}
+
+	// Write conflicted files with conflict markers
+	for _, path := range result.Conflicts {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(path, result.WorkingContents[path], 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Remove files that are deleted by merge
+	conflicted := make(map[string]bool)
+	for _, path := range result.Conflicts {
+		conflicted[path] = true
+	}
+	for path := range indexFiles {
+		if _, ok := resultFiles[path]; !ok && !conflicted[path] {
+			err = RemoveWorkingFile(path)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return WriteIndexFile(newEntries)
+}
@@ -0,0 +1,200 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Object types that can be stored inside .git-go/objects
+const (
+	BlobObject   = "blob"
+	TreeObject   = "tree"
+	CommitObject = "commit"
+	TagObject    = "tag"
+)
+
+// Check the given object type is one of the known object types
+func IsValidObjectType(objType string) bool {
+	switch objType {
+	case BlobObject, TreeObject, CommitObject, TagObject:
+		return true
+	}
+	return false
+}
+
+// Add object header in front of object content (e.g "blob 12\x00hello world\n")
+func EncodeObject(objType string, content []byte) []byte {
+	header := fmt.Sprintf("%s %d\x00", objType, len(content))
+
+	encoded := make([]byte, 0, len(header)+len(content))
+	encoded = append(encoded, header...)
+	encoded = append(encoded, content...)
+
+	return encoded
+}
+
+// Parse and validate object header, then return object type and object content
+func DecodeObject(data []byte) (string, []byte, error) {
+	nullIndex := bytes.IndexByte(data, 0)
+	if nullIndex == -1 {
+		return "", nil, errors.New("invalid object: missing header terminator")
+	}
+
+	// Header format is "<type> <size>"
+	headerParts := bytes.SplitN(data[:nullIndex], []byte(" "), 2)
+	if len(headerParts) != 2 {
+		return "", nil, errors.New("invalid object: malformed header")
+	}
+
+	objType := string(headerParts[0])
+	if !IsValidObjectType(objType) {
+		return "", nil, fmt.Errorf("invalid object: unknown type '%s'", objType)
+	}
+
+	size, err := strconv.Atoi(string(headerParts[1]))
+	if err != nil || size < 0 {
+		return "", nil, fmt.Errorf("invalid object: bad size '%s'", headerParts[1])
+	}
+
+	content := data[nullIndex+1:]
+	if len(content) != size {
+		return "", nil, fmt.Errorf("invalid object: size mismatch (header %d, actual %d)", size, len(content))
+	}
+
+	return objType, content, nil
+}
+
+// Get hash value of object content together with its header (same as `git hash-object`)
+func HashObject(objType string, content []byte) (string, error) {
+	return HandFileContent(EncodeObject(objType, content))
+}
+
+// Get the path of object file inside objects folder from hash value
+func ObjectPath(hashValue string) string {
+	return GitPath("objects", hashValue[:2], hashValue[2:])
+}
+
+// Write object to objects folder with compression and return hash value of the object
+func WriteObject(objType string, content []byte) (string, error) {
+	encoded := EncodeObject(objType, content)
+
+	hashValue, err := HandFileContent(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	// Object is already stored (as loose object or inside pack), don't need to write again
+	if HasObject(hashValue) {
+		return hashValue, nil
+	}
+
+	objectPath := ObjectPath(hashValue)
+
+	err = os.MkdirAll(GitPath("objects", hashValue[:2]), 0755)
+	if err != nil {
+		return "", err
+	}
+
+	var compressBuf bytes.Buffer
+	err = CompressContent(&compressBuf, encoded)
+	if err != nil {
+		return "", err
+	}
+
+	// Interrupted write must not leave truncated object, existing object is never written again
+	err = writeFileAtomic(objectPath, compressBuf.Bytes(), 0444)
+	if err != nil {
+		return "", err
+	}
+
+	return hashValue, nil
+}
+
+// Check object is stored as loose object or inside pack
+func HasObject(hashValue string) bool {
+	if len(hashValue) < 3 {
+		return false
+	}
+	if _, err := os.Stat(ObjectPath(hashValue)); err == nil {
+		return true
+	}
+	return IsPackedObject(hashValue)
+}
+
+// Read object from objects folder (loose object first, then packs) and return object type and object content
+func ReadObject(hashValue string) (string, []byte, error) {
+	if len(hashValue) < 3 {
+		return "", nil, fmt.Errorf("invalid object name '%s'", hashValue)
+	}
+
+	compressedContent, err := os.ReadFile(ObjectPath(hashValue))
+	if os.IsNotExist(err) {
+		objType, content, found, err := ReadPackedObject(hashValue)
+		if err != nil {
+			return "", nil, fmt.Errorf("cannot read packed object '%s': %v", hashValue, err)
+		}
+		if !found {
+			return "", nil, fmt.Errorf("object '%s' not found", hashValue)
+		}
+		return objType, content, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	decompressedContent, err := DecompressContent(bytes.NewBuffer(compressedContent))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return DecodeObject(decompressedContent)
+}
+
+// List hash values of every loose object (object stored as single file inside objects folder)
+func ListLooseObjects() ([]string, error) {
+	var hashes []string
+
+	dirEntries, err := os.ReadDir(GitPath("objects"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dirEntry := range dirEntries {
+		// Object folders are named by first two characters of hash value, others (e.g pack) are skipped
+		if !dirEntry.IsDir() || len(dirEntry.Name()) != 2 || !isHexHash(dirEntry.Name()+"00") {
+			continue
+		}
+
+		objectEntries, err := os.ReadDir(GitPath("objects", dirEntry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, objectEntry := range objectEntries {
+			hashValue := dirEntry.Name() + objectEntry.Name()
+			if len(hashValue) == 40 && isHexHash(hashValue) {
+				hashes = append(hashes, hashValue)
+			}
+		}
+	}
+
+	return hashes, nil
+}
+
+// Read blob object and return the stored file content
+func ReadBlobObject(hashValue string) ([]byte, error) {
+	objType, content, err := ReadObject(hashValue)
+	if err != nil {
+		return nil, err
+	}
+	if objType != BlobObject {
+		return nil, fmt.Errorf("object '%s' is a %s, not a blob", hashValue, objType)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,1008 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Object type numbers stored in pack entry header
+const (
+	packCommit   = 1
+	packTree     = 2
+	packBlob     = 3
+	packTag      = 4
+	packOfsDelta = 6 // Delta against base found by offset inside the same pack
+	packRefDelta = 7 // Delta against base found by hash value
+)
+
+// Number of delta base objects kept in memory for each pack
+const deltaBaseCacheSize = 64
+
+var packTypeNumbers = map[string]int{
+	CommitObject: packCommit,
+	TreeObject:   packTree,
+	BlobObject:   packBlob,
+	TagObject:    packTag,
+}
+
+var packTypeNames = map[int]string{
+	packCommit: CommitObject,
+	packTree:   TreeObject,
+	packBlob:   BlobObject,
+	packTag:    TagObject,
+}
+
+// Magic number of pack index file version 2 ("\377tOc")
+var packIndexMagic = []byte{0xff, 't', 'O', 'c'}
+
+// Index of one pack file, which maps object hash values to offsets inside the pack
+type PackIndex struct {
+	PackPath string   // Path of .pack file
+	Hashes   []string // Hash values of packed objects in sorted order
+	Offsets  []int64  // Offset of each object inside pack file
+	CRCs     []uint32 // CRC32 of each packed entry (header and compressed data)
+
+	packFile  *os.File               // Pack file opened on first object read
+	baseCache map[int64]cachedObject // Recently read delta base objects by offset
+}
+
+// Object content kept in delta base cache
+type cachedObject struct {
+	objType string
+	content []byte
+}
+
+// Packs loaded from objects/pack, they are loaded once and reloaded after ResetPacks
+var (
+	loadedPacks []*PackIndex
+	packsLoaded bool
+)
+
+// Get the path of pack folder inside objects folder
+func PackDir() string {
+	return GitPath("objects", "pack")
+}
+
+// Get every pack of repository, the .idx files are read on first use
+func Packs() []*PackIndex {
+	if packsLoaded {
+		return loadedPacks
+	}
+	packsLoaded = true
+
+	idxPaths, _ := filepath.Glob(filepath.Join(PackDir(), "pack-*.idx"))
+	sort.Strings(idxPaths)
+	for _, idxPath := range idxPaths {
+		index, err := ReadPackIndex(idxPath)
+		if err != nil {
+			// Broken or half-written pack is skipped, loose objects or other packs may still have the objects
+			continue
+		}
+		loadedPacks = append(loadedPacks, index)
+	}
+
+	return loadedPacks
+}
+
+// Forget loaded packs so packs written or removed afterwards are seen
+func ResetPacks() {
+	for _, index := range loadedPacks {
+		if index.packFile != nil {
+			index.packFile.Close()
+		}
+	}
+	loadedPacks = nil
+	packsLoaded = false
+}
+
+// Find offset of object inside pack file
+func (index *PackIndex) Find(hashValue string) (int64, bool) {
+	i := sort.SearchStrings(index.Hashes, hashValue)
+	if i < len(index.Hashes) && index.Hashes[i] == hashValue {
+		return index.Offsets[i], true
+	}
+	return 0, false
+}
+
+// Find hash values of packed objects that start with the given prefix
+func (index *PackIndex) FindPrefix(prefix string) []string {
+	var matches []string
+	for i := sort.SearchStrings(index.Hashes, prefix); i < len(index.Hashes) && strings.HasPrefix(index.Hashes[i], prefix); i++ {
+		matches = append(matches, index.Hashes[i])
+	}
+	return matches
+}
+
+// Read and validate pack index file (version 2)
+func ReadPackIndex(idxPath string) (*PackIndex, error) {
+	data, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header (8 bytes), fanout table (256 * 4 bytes) and two checksums at the end
+	if len(data) < 8+256*4+2*sha1.Size {
+		return nil, fmt.Errorf("pack index '%s' is too small", idxPath)
+	}
+	if !bytes.Equal(data[:4], packIndexMagic) || binary.BigEndian.Uint32(data[4:8]) != 2 {
+		return nil, fmt.Errorf("pack index '%s' has unsupported version", idxPath)
+	}
+
+	checksum := sha1.Sum(data[:len(data)-sha1.Size])
+	if !bytes.Equal(checksum[:], data[len(data)-sha1.Size:]) {
+		return nil, fmt.Errorf("pack index '%s' is corrupted: checksum mismatch", idxPath)
+	}
+
+	count := int(binary.BigEndian.Uint32(data[8+255*4 : 8+256*4]))
+	hashStart := 8 + 256*4
+	crcStart := hashStart + count*sha1.Size
+	offsetStart := crcStart + count*4
+	largeOffsetStart := offsetStart + count*4
+	if largeOffsetStart+2*sha1.Size > len(data) {
+		return nil, fmt.Errorf("pack index '%s' is truncated", idxPath)
+	}
+
+	index := &PackIndex{
+		PackPath: strings.TrimSuffix(idxPath, ".idx") + ".pack",
+		Hashes:   make([]string, count),
+		Offsets:  make([]int64, count),
+		CRCs:     make([]uint32, count),
+	}
+
+	for i := 0; i < count; i++ {
+		index.Hashes[i] = hex.EncodeToString(data[hashStart+i*sha1.Size : hashStart+(i+1)*sha1.Size])
+		index.CRCs[i] = binary.BigEndian.Uint32(data[crcStart+i*4:])
+
+		offset := binary.BigEndian.Uint32(data[offsetStart+i*4:])
+		if offset&0x80000000 == 0 {
+			index.Offsets[i] = int64(offset)
+			continue
+		}
+
+		// Offset bigger than 31 bits is stored in large offset table
+		largeOffsetPosition := largeOffsetStart + int(offset&0x7fffffff)*8
+		if largeOffsetPosition+8 > len(data)-2*sha1.Size {
+			return nil, fmt.Errorf("pack index '%s' has invalid large offset", idxPath)
+		}
+		index.Offsets[i] = int64(binary.BigEndian.Uint64(data[largeOffsetPosition:]))
+	}
+
+	return index, nil
+}
+
+// Encode pack index file (version 2) for the given pack, entries must be sorted by hash value
+func EncodePackIndex(index *PackIndex, packChecksum []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(packIndexMagic)
+	binary.Write(&buf, binary.BigEndian, uint32(2))
+
+	// Fanout table, entry N is number of objects whose first byte is less than or equal to N
+	var fanout [256]uint32
+	for _, hashValue := range index.Hashes {
+		firstByte, err := hex.DecodeString(hashValue[:2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid object name '%s'", hashValue)
+		}
+		for i := int(firstByte[0]); i < 256; i++ {
+			fanout[i]++
+		}
+	}
+	binary.Write(&buf, binary.BigEndian, fanout)
+
+	for _, hashValue := range index.Hashes {
+		rawHash, err := hex.DecodeString(hashValue)
+		if err != nil || len(rawHash) != sha1.Size {
+			return nil, fmt.Errorf("invalid object name '%s'", hashValue)
+		}
+		buf.Write(rawHash)
+	}
+
+	for _, crc := range index.CRCs {
+		binary.Write(&buf, binary.BigEndian, crc)
+	}
+
+	var largeOffsets []int64
+	for _, offset := range index.Offsets {
+		if offset < 0x80000000 {
+			binary.Write(&buf, binary.BigEndian, uint32(offset))
+			continue
+		}
+		binary.Write(&buf, binary.BigEndian, uint32(0x80000000|len(largeOffsets)))
+		largeOffsets = append(largeOffsets, offset)
+	}
+	for _, offset := range largeOffsets {
+		binary.Write(&buf, binary.BigEndian, uint64(offset))
+	}
+
+	buf.Write(packChecksum)
+	checksum := sha1.Sum(buf.Bytes())
+	buf.Write(checksum[:])
+
+	return buf.Bytes(), nil
+}
+
+// Encode type and size header of pack entry (size uses 4 bits in first byte, then 7 bits per byte)
+func encodePackEntryHeader(typeNumber int, size int) []byte {
+	header := []byte{byte(typeNumber<<4) | byte(size&0x0f)}
+	size >>= 4
+	for size > 0 {
+		header[len(header)-1] |= 0x80
+		header = append(header, byte(size&0x7f))
+		size >>= 7
+	}
+	return header
+}
+
+// Decode type and size header of pack entry, the number of header bytes is returned too
+func decodePackEntryHeader(data []byte) (int, int, int, error) {
+	if len(data) == 0 {
+		return 0, 0, 0, errors.New("pack entry header is truncated")
+	}
+
+	typeNumber := int(data[0]>>4) & 0x07
+	size := int(data[0] & 0x0f)
+	shift := 4
+	used := 1
+	for data[used-1]&0x80 != 0 {
+		if used >= len(data) || shift > 56 {
+			return 0, 0, 0, errors.New("pack entry header is truncated")
+		}
+		size |= int(data[used]&0x7f) << shift
+		shift += 7
+		used++
+	}
+
+	return typeNumber, size, used, nil
+}
+
+// Header of one pack entry
+type packEntry struct {
+	typeNumber int
+	size       int    // Size of inflated data (size of delta data for delta entries)
+	dataOffset int64  // Offset where compressed data starts
+	baseOffset int64  // Offset of delta base for OFS_DELTA entry
+	baseHash   string // Hash value of delta base for REF_DELTA entry
+}
+
+// Decode negative offset of OFS_DELTA base (7 bits per byte, most significant group first and each continuation adds one)
+func decodeOfsDeltaOffset(data []byte) (int64, int, error) {
+	if len(data) == 0 {
+		return 0, 0, errors.New("delta base offset is truncated")
+	}
+
+	offset := int64(data[0] & 0x7f)
+	used := 1
+	for data[used-1]&0x80 != 0 {
+		if used >= len(data) {
+			return 0, 0, errors.New("delta base offset is truncated")
+		}
+		offset = ((offset + 1) << 7) | int64(data[used]&0x7f)
+		used++
+	}
+
+	return offset, used, nil
+}
+
+// Encode negative offset of OFS_DELTA base
+func encodeOfsDeltaOffset(offset int64) []byte {
+	encoded := []byte{byte(offset & 0x7f)}
+	for offset >>= 7; offset > 0; offset >>= 7 {
+		offset--
+		encoded = append([]byte{byte(offset&0x7f) | 0x80}, encoded...)
+	}
+	return encoded
+}
+
+// Read header of entry at the given offset of pack file
+func (index *PackIndex) readEntry(offset int64) (packEntry, error) {
+	if index.packFile == nil {
+		packFile, err := os.Open(index.PackPath)
+		if err != nil {
+			return packEntry{}, err
+		}
+		index.packFile = packFile
+	}
+
+	// Entry header with delta base is never longer than a few bytes
+	headerBuf := make([]byte, 64)
+	n, err := index.packFile.ReadAt(headerBuf, offset)
+	if err != nil && err != io.EOF {
+		return packEntry{}, err
+	}
+
+	return parsePackEntry(headerBuf[:n], offset)
+}
+
+// Parse header of pack entry that starts at the given offset from data of the entry
+func parsePackEntry(data []byte, offset int64) (packEntry, error) {
+	typeNumber, size, used, err := decodePackEntryHeader(data)
+	if err != nil {
+		return packEntry{}, err
+	}
+	entry := packEntry{typeNumber: typeNumber, size: size}
+
+	switch typeNumber {
+	case packOfsDelta:
+		negativeOffset, n, err := decodeOfsDeltaOffset(data[used:])
+		if err != nil {
+			return packEntry{}, err
+		}
+		if negativeOffset <= 0 || negativeOffset > offset {
+			return packEntry{}, fmt.Errorf("pack entry at offset %d has invalid delta base offset", offset)
+		}
+		entry.baseOffset = offset - negativeOffset
+		used += n
+	case packRefDelta:
+		if len(data) < used+sha1.Size {
+			return packEntry{}, errors.New("delta base name is truncated")
+		}
+		entry.baseHash = hex.EncodeToString(data[used : used+sha1.Size])
+		used += sha1.Size
+	default:
+		if _, ok := packTypeNames[typeNumber]; !ok {
+			return packEntry{}, fmt.Errorf("pack entry at offset %d has unsupported type %d", offset, typeNumber)
+		}
+	}
+
+	entry.dataOffset = offset + int64(used)
+	return entry, nil
+}
+
+// Decompress zlib data of pack entry that starts at the given offset
+func (index *PackIndex) inflateAt(offset int64, size int) ([]byte, error) {
+	decompressReader, err := zlib.NewReader(io.NewSectionReader(index.packFile, offset, 1<<62))
+	if err != nil {
+		return nil, err
+	}
+	defer decompressReader.Close()
+
+	content := make([]byte, size)
+	if _, err := io.ReadFull(decompressReader, content); err != nil {
+		return nil, fmt.Errorf("pack entry at offset %d is corrupted: %v", offset, err)
+	}
+
+	return content, nil
+}
+
+// Read object stored at the given offset of pack file, delta chains are resolved
+func (index *PackIndex) ReadObjectAt(offset int64) (string, []byte, error) {
+	entry, err := index.readEntry(offset)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := index.inflateAt(entry.dataOffset, entry.size)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var baseType string
+	var base []byte
+	switch entry.typeNumber {
+	case packOfsDelta:
+		baseType, base, err = index.readDeltaBase(entry.baseOffset)
+	case packRefDelta:
+		// Base is usually inside the same pack, but thin pack may refer to object stored anywhere
+		if baseOffset, ok := index.Find(entry.baseHash); ok {
+			baseType, base, err = index.readDeltaBase(baseOffset)
+		} else {
+			baseType, base, err = ReadObject(entry.baseHash)
+		}
+	default:
+		return packTypeNames[entry.typeNumber], data, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot read delta base of entry at offset %d: %v", offset, err)
+	}
+
+	content, err := ApplyDelta(base, data)
+	if err != nil {
+		return "", nil, fmt.Errorf("pack entry at offset %d has invalid delta: %v", offset, err)
+	}
+
+	return baseType, content, nil
+}
+
+// Read delta base object, recently used bases are cached since objects of same delta chain share them
+func (index *PackIndex) readDeltaBase(offset int64) (string, []byte, error) {
+	if cached, ok := index.baseCache[offset]; ok {
+		return cached.objType, cached.content, nil
+	}
+
+	objType, content, err := index.ReadObjectAt(offset)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if index.baseCache == nil || len(index.baseCache) >= deltaBaseCacheSize {
+		index.baseCache = make(map[int64]cachedObject)
+	}
+	index.baseCache[offset] = cachedObject{objType, content}
+
+	return objType, content, nil
+}
+
+// Find object in packs, false is returned when no pack has the object
+func ReadPackedObject(hashValue string) (string, []byte, bool, error) {
+	for _, index := range Packs() {
+		offset, ok := index.Find(hashValue)
+		if !ok {
+			continue
+		}
+		objType, content, err := index.ReadObjectAt(offset)
+		return objType, content, true, err
+	}
+	return "", nil, false, nil
+}
+
+// Check object is stored in any pack
+func IsPackedObject(hashValue string) bool {
+	for _, index := range Packs() {
+		if _, ok := index.Find(hashValue); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// List hash values of every packed object without duplicates
+func ListPackedObjects() []string {
+	seen := make(map[string]bool)
+	var hashes []string
+	for _, index := range Packs() {
+		for _, hashValue := range index.Hashes {
+			if !seen[hashValue] {
+				seen[hashValue] = true
+				hashes = append(hashes, hashValue)
+			}
+		}
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+// Options for encoding pack file
+type PackOptions struct {
+	Window   int  // Number of previous objects tried as delta base, 0 disables delta compression
+	MaxDepth int  // Longest allowed delta chain
+	RefDelta bool // Refer to delta base by hash value (REF_DELTA) instead of offset (OFS_DELTA)
+}
+
+// Same defaults as Git (--window=10 --depth=50)
+var DefaultPackOptions = PackOptions{Window: 10, MaxDepth: 50}
+
+// Object waiting to be written into pack
+type packObject struct {
+	hashValue  string
+	objType    string
+	content    []byte
+	nameHint   string      // File or folder name of object, used to place similar objects near each other
+	depth      int         // Length of delta chain of the object (0 for full object)
+	deltaIndex *DeltaIndex // Index of content while object is inside delta window
+}
+
+// Give blobs and trees the name they have inside trees of the pack, versions of the same file then become neighbours
+func assignNameHints(objects []*packObject) {
+	names := make(map[string]string)
+	for _, object := range objects {
+		if object.objType != TreeObject {
+			continue
+		}
+		entries, err := ParseTree(object.content)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if _, ok := names[entry.Hash]; !ok {
+				names[entry.Hash] = entry.Name
+			}
+		}
+	}
+
+	for _, object := range objects {
+		object.nameHint = names[object.hashValue]
+	}
+}
+
+// Encode pack containing the given objects, similar objects are stored as deltas against each other
+// Pack data (with trailing checksum) and pack index describing it are returned
+func EncodePack(hashes []string, options PackOptions) ([]byte, *PackIndex, error) {
+	var objects []*packObject
+	for _, hashValue := range hashes {
+		objType, content, err := ReadObject(hashValue)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot read object '%s': %v", hashValue, err)
+		}
+		objects = append(objects, &packObject{hashValue: hashValue, objType: objType, content: content})
+	}
+	assignNameHints(objects)
+
+	// Same as Git, group objects by type and name, bigger first, so delta bases are the newer and larger versions
+	sort.SliceStable(objects, func(i, j int) bool {
+		if objects[i].objType != objects[j].objType {
+			return packTypeNumbers[objects[i].objType] < packTypeNumbers[objects[j].objType]
+		}
+		if objects[i].nameHint != objects[j].nameHint {
+			return objects[i].nameHint < objects[j].nameHint
+		}
+		if len(objects[i].content) != len(objects[j].content) {
+			return len(objects[i].content) > len(objects[j].content)
+		}
+		return objects[i].hashValue < objects[j].hashValue
+	})
+
+	var packBuf bytes.Buffer
+	packBuf.WriteString("PACK")
+	binary.Write(&packBuf, binary.BigEndian, uint32(2))
+	binary.Write(&packBuf, binary.BigEndian, uint32(len(objects)))
+
+	index := &PackIndex{}
+	offsets := make(map[string]int64)
+	crcs := make(map[string]uint32)
+	for i, object := range objects {
+		offset := int64(packBuf.Len())
+
+		// Try previous objects inside sliding window as delta base and keep the smallest delta
+		var delta []byte
+		var base *packObject
+		for j := i - 1; j >= 0 && j >= i-options.Window; j-- {
+			candidate := objects[j]
+			if candidate.objType != object.objType || candidate.depth >= options.MaxDepth {
+				continue
+			}
+
+			// Delta must save at least half of the object to be worth the extra work when reading
+			maxSize := len(object.content)/2 - 20
+			if delta != nil {
+				maxSize = len(delta) - 1
+			}
+			if maxSize <= 0 {
+				continue
+			}
+
+			if candidate.deltaIndex == nil {
+				candidate.deltaIndex = NewDeltaIndex(candidate.content)
+			}
+			if candidateDelta := candidate.deltaIndex.CreateDelta(object.content); len(candidateDelta) <= maxSize {
+				delta, base = candidateDelta, candidate
+			}
+		}
+
+		var entry []byte
+		var compressBuf bytes.Buffer
+		if base != nil {
+			object.depth = base.depth + 1
+			if options.RefDelta {
+				rawHash, _ := hex.DecodeString(base.hashValue)
+				entry = append(encodePackEntryHeader(packRefDelta, len(delta)), rawHash...)
+			} else {
+				entry = append(encodePackEntryHeader(packOfsDelta, len(delta)), encodeOfsDeltaOffset(offset-offsets[base.hashValue])...)
+			}
+			if err := CompressContent(&compressBuf, delta); err != nil {
+				return nil, nil, err
+			}
+		} else {
+			entry = encodePackEntryHeader(packTypeNumbers[object.objType], len(object.content))
+			if err := CompressContent(&compressBuf, object.content); err != nil {
+				return nil, nil, err
+			}
+		}
+		entry = append(entry, compressBuf.Bytes()...)
+		packBuf.Write(entry)
+
+		offsets[object.hashValue] = offset
+		crcs[object.hashValue] = crc32.ChecksumIEEE(entry)
+		index.Hashes = append(index.Hashes, object.hashValue)
+
+		// Object that left the window will never be a delta base again
+		if i-options.Window >= 0 {
+			objects[i-options.Window].deltaIndex = nil
+		}
+	}
+
+	packChecksum := sha1.Sum(packBuf.Bytes())
+	packBuf.Write(packChecksum[:])
+
+	// Index lists objects in hash value order
+	sort.Strings(index.Hashes)
+	for _, hashValue := range index.Hashes {
+		index.Offsets = append(index.Offsets, offsets[hashValue])
+		index.CRCs = append(index.CRCs, crcs[hashValue])
+	}
+
+	return packBuf.Bytes(), index, nil
+}
+
+// Write pack file and its index containing the given objects, then return the pack name (e.g pack-<checksum>)
+func WritePack(hashes []string, options PackOptions) (string, error) {
+	packData, index, err := EncodePack(hashes, options)
+	if err != nil {
+		return "", err
+	}
+
+	return savePack(packData, index)
+}
+
+// Reader that keeps copy of every byte read from underlying reader
+// It is also a byte reader, so zlib never reads past the end of compressed data
+type recordingReader struct {
+	r    *bufio.Reader
+	data []byte
+}
+
+func (rr *recordingReader) Read(p []byte) (int, error) {
+	n, err := rr.r.Read(p)
+	rr.data = append(rr.data, p[:n]...)
+	return n, err
+}
+
+func (rr *recordingReader) ReadByte() (byte, error) {
+	b, err := rr.r.ReadByte()
+	if err == nil {
+		rr.data = append(rr.data, b)
+	}
+	return b, err
+}
+
+// Read one pack from stream (e.g pack pushed to receive-pack), length of pack is only known by walking its entries
+// Nothing after trailing checksum is read from stream
+func ReadPackStream(r *bufio.Reader) ([]byte, error) {
+	stream := &recordingReader{r: r}
+
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(stream, header); err != nil {
+		return nil, fmt.Errorf("invalid pack: %v", err)
+	}
+	if !bytes.HasPrefix(header, []byte("PACK")) {
+		return nil, errors.New("invalid pack: bad header")
+	}
+
+	// Skip variable length number (7 bits per byte), used by entry header and delta base offset
+	skipVarint := func() (byte, error) {
+		first, err := stream.ReadByte()
+		for b := first; err == nil && b&0x80 != 0; {
+			b, err = stream.ReadByte()
+		}
+		return first, err
+	}
+
+	count := binary.BigEndian.Uint32(header[8:12])
+	for i := uint32(0); i < count; i++ {
+		first, err := skipVarint()
+		if err != nil {
+			return nil, fmt.Errorf("invalid pack: entry %d: %v", i, err)
+		}
+
+		switch int(first>>4) & 0x07 {
+		case packOfsDelta:
+			_, err = skipVarint()
+		case packRefDelta:
+			_, err = io.ReadFull(stream, make([]byte, sha1.Size))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid pack: entry %d: %v", i, err)
+		}
+
+		decompressReader, err := zlib.NewReader(stream)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pack: entry %d: %v", i, err)
+		}
+		if _, err := io.Copy(io.Discard, decompressReader); err != nil {
+			return nil, fmt.Errorf("invalid pack: entry %d: %v", i, err)
+		}
+	}
+
+	if _, err := io.ReadFull(stream, make([]byte, sha1.Size)); err != nil {
+		return nil, fmt.Errorf("invalid pack: missing checksum: %v", err)
+	}
+
+	return stream.data, nil
+}
+
+// Store pack received from another repository, every object is checked and pack index is built for it
+// Pack name is returned, empty name means pack has no objects
+func StorePack(packData []byte) (string, error) {
+	if len(packData) < 12+sha1.Size || !bytes.HasPrefix(packData, []byte("PACK")) {
+		return "", errors.New("invalid pack: bad header")
+	}
+	if version := binary.BigEndian.Uint32(packData[4:8]); version != 2 && version != 3 {
+		return "", fmt.Errorf("invalid pack: unsupported version %d", version)
+	}
+
+	end := len(packData) - sha1.Size
+	packChecksum := sha1.Sum(packData[:end])
+	if !bytes.Equal(packChecksum[:], packData[end:]) {
+		return "", errors.New("invalid pack: checksum mismatch")
+	}
+
+	count := int(binary.BigEndian.Uint32(packData[8:12]))
+	if count == 0 {
+		return "", nil
+	}
+
+	type receivedEntry struct {
+		offset int64
+		entry  packEntry
+		data   []byte // Inflated object content or delta data
+		crc    uint32
+	}
+
+	// Walk entries one after another, compressed length is only known after inflating
+	entries := make([]receivedEntry, 0, count)
+	position := int64(12)
+	for i := 0; i < count; i++ {
+		if position >= int64(end) {
+			return "", errors.New("invalid pack: truncated")
+		}
+		entry, err := parsePackEntry(packData[position:min(position+64, int64(end))], position)
+		if err != nil {
+			return "", fmt.Errorf("invalid pack: %v", err)
+		}
+
+		dataReader := bytes.NewReader(packData[entry.dataOffset:end])
+		decompressReader, err := zlib.NewReader(dataReader)
+		if err != nil {
+			return "", fmt.Errorf("invalid pack: entry at offset %d: %v", position, err)
+		}
+		// Size in entry header comes from remote, so buffer only grows with data that is really inflated
+		data, err := io.ReadAll(io.LimitReader(decompressReader, int64(entry.size)+1))
+		if err != nil {
+			return "", fmt.Errorf("invalid pack: entry at offset %d: %v", position, err)
+		}
+		// Read until end of zlib stream so its checksum is verified and consumed
+		if extra, err := io.Copy(io.Discard, decompressReader); err != nil || extra != 0 || len(data) != entry.size {
+			return "", fmt.Errorf("invalid pack: entry at offset %d has wrong size", position)
+		}
+
+		next := int64(end) - int64(dataReader.Len())
+		entries = append(entries, receivedEntry{
+			offset: position,
+			entry:  entry,
+			data:   data,
+			crc:    crc32.ChecksumIEEE(packData[position:next]),
+		})
+		position = next
+	}
+	if position != int64(end) {
+		return "", errors.New("invalid pack: unexpected data after last entry")
+	}
+
+	// Resolve full objects first, then deltas whose base is known until every entry is resolved
+	type resolvedObject struct {
+		objType   string
+		content   []byte
+		hashValue string
+	}
+	resolved := make(map[int64]resolvedObject)
+	offsetsByHash := make(map[string]int64)
+	var thinBases []resolvedObject // Delta bases of thin pack that are read from this repository
+
+	resolve := func(received receivedEntry, objType string, content []byte) error {
+		hashValue, err := HashObject(objType, content)
+		if err != nil {
+			return err
+		}
+		resolved[received.offset] = resolvedObject{objType, content, hashValue}
+		if _, ok := offsetsByHash[hashValue]; !ok {
+			offsetsByHash[hashValue] = received.offset
+		}
+		return nil
+	}
+
+	for isProgress := true; isProgress && len(resolved) < len(entries); {
+		isProgress = false
+		for _, received := range entries {
+			if _, ok := resolved[received.offset]; ok {
+				continue
+			}
+
+			var baseType string
+			var base []byte
+			switch received.entry.typeNumber {
+			case packOfsDelta:
+				baseObject, ok := resolved[received.entry.baseOffset]
+				if !ok {
+					continue
+				}
+				baseType, base = baseObject.objType, baseObject.content
+			case packRefDelta:
+				if baseOffset, ok := offsetsByHash[received.entry.baseHash]; ok {
+					baseType, base = resolved[baseOffset].objType, resolved[baseOffset].content
+				} else if HasObject(received.entry.baseHash) {
+					// Base of thin pack is already stored in this repository
+					var err error
+					baseType, base, err = ReadObject(received.entry.baseHash)
+					if err != nil {
+						return "", err
+					}
+					thinBases = append(thinBases, resolvedObject{baseType, base, received.entry.baseHash})
+				} else {
+					continue
+				}
+			default:
+				if err := resolve(received, packTypeNames[received.entry.typeNumber], received.data); err != nil {
+					return "", err
+				}
+				isProgress = true
+				continue
+			}
+
+			content, err := ApplyDelta(base, received.data)
+			if err != nil {
+				return "", fmt.Errorf("invalid pack: entry at offset %d: %v", received.offset, err)
+			}
+			if err := resolve(received, baseType, content); err != nil {
+				return "", err
+			}
+			isProgress = true
+		}
+	}
+	if len(resolved) < len(entries) {
+		return "", fmt.Errorf("invalid pack: %d deltas have unknown base", len(entries)-len(resolved))
+	}
+
+	// Thin pack is completed by appending its missing bases, so stored pack never depends on other objects
+	if len(thinBases) > 0 {
+		var packBuf bytes.Buffer
+		packBuf.Write(packData[:end])
+		for _, base := range thinBases {
+			if _, ok := offsetsByHash[base.hashValue]; ok {
+				continue // Base was already appended or pack has it as another entry
+			}
+
+			var compressBuf bytes.Buffer
+			if err := CompressContent(&compressBuf, base.content); err != nil {
+				return "", err
+			}
+			entry := append(encodePackEntryHeader(packTypeNumbers[base.objType], len(base.content)), compressBuf.Bytes()...)
+
+			offset := int64(packBuf.Len())
+			entries = append(entries, receivedEntry{offset: offset, crc: crc32.ChecksumIEEE(entry)})
+			resolved[offset] = base
+			offsetsByHash[base.hashValue] = offset
+			packBuf.Write(entry)
+		}
+
+		completed := packBuf.Bytes()
+		binary.BigEndian.PutUint32(completed[8:12], uint32(len(entries)))
+		packChecksum := sha1.Sum(completed)
+		packData = append(completed, packChecksum[:]...)
+	}
+
+	index := &PackIndex{}
+	crcs := make(map[string]uint32)
+	for _, received := range entries {
+		hashValue := resolved[received.offset].hashValue
+		if offsetsByHash[hashValue] != received.offset {
+			continue // Same object stored twice, index keeps the first one
+		}
+		index.Hashes = append(index.Hashes, hashValue)
+		crcs[hashValue] = received.crc
+	}
+	sort.Strings(index.Hashes)
+	for _, hashValue := range index.Hashes {
+		index.Offsets = append(index.Offsets, offsetsByHash[hashValue])
+		index.CRCs = append(index.CRCs, crcs[hashValue])
+	}
+
+	return savePack(packData, index)
+}
+
+// Write pack data and its index into pack folder, then return the pack name
+func savePack(packData []byte, index *PackIndex) (string, error) {
+	packChecksum := packData[len(packData)-sha1.Size:]
+	idxContent, err := EncodePackIndex(index, packChecksum)
+	if err != nil {
+		return "", err
+	}
+
+	packName := "pack-" + hex.EncodeToString(packChecksum)
+	err = os.MkdirAll(PackDir(), 0755)
+	if err != nil {
+		return "", err
+	}
+
+	// Pack is written before its index, so index file never points to missing pack
+	err = writeFileAtomic(filepath.Join(PackDir(), packName+".pack"), packData, 0444)
+	if err != nil {
+		return "", err
+	}
+	err = writeFileAtomic(filepath.Join(PackDir(), packName+".idx"), idxContent, 0444)
+	if err != nil {
+		return "", err
+	}
+
+	ResetPacks()
+
+	return packName, nil
+}
+
+// Write file through temporary file and rename, so readers never see half-written file
+// Temporary file has unique name, so processes writing the same file at the same time don't clash
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "tmp_"+filepath.Base(path)+"_")
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+
+	_, err = tempFile.Write(content)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempPath, perm)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	// Read-only file left by previous write can't be replaced on some systems
+	os.Remove(path)
+
+	err = os.Rename(tempPath, path)
+	if err != nil {
+		os.Remove(tempPath)
+	}
+	return err
+}
+
+// Remove pack file and its index
+func RemovePack(packName string) error {
+	for _, ext := range []string{".idx", ".pack"} {
+		err := os.Remove(filepath.Join(PackDir(), packName+ext))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	ResetPacks()
+
+	return nil
+}
+
+// Get pack name of pack index (e.g pack-<checksum>)
+func (index *PackIndex) Name() string {
+	return strings.TrimSuffix(filepath.Base(index.PackPath), ".pack")
+}
+
+// Remove loose objects that are already stored in packs, then return how many objects are removed
+func PruneLooseObjects() (int, error) {
+	looseObjects, err := ListLooseObjects()
+	if err != nil {
+		return 0, err
+	}
+
+	pruned := 0
+	for _, hashValue := range looseObjects {
+		if !IsPackedObject(hashValue) {
+			continue
+		}
+		err = os.Remove(ObjectPath(hashValue))
+		if err != nil {
+			return pruned, err
+		}
+		pruned++
+
+		// Remove object folder when it becomes empty
+		os.Remove(filepath.Dir(ObjectPath(hashValue)))
+	}
+
+	return pruned, nil
+}
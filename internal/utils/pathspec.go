@@ -0,0 +1,68 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Check pathspec has glob characters, so it is matched as pattern instead of path
+func IsGlobPathspec(pathspec string) bool {
+	return strings.ContainsAny(pathspec, "*?[")
+}
+
+// Check path (relative to working tree root) matches pathspec, which is file, folder (every path inside matches) or glob pattern
+// Unlike .gitignore patterns, "*" in pathspec matches "/" too (e.g "*.go" matches "cmd/add.go")
+func MatchPathspec(pathspec string, filePath string) bool {
+	if pathspec == "" || pathspec == "." {
+		return true
+	}
+	if filePath == pathspec || strings.HasPrefix(filePath, strings.TrimSuffix(pathspec, "/")+"/") {
+		return true
+	}
+	if !IsGlobPathspec(pathspec) {
+		return false
+	}
+
+	matcher, err := regexp.Compile(globToRegexp(pathspec))
+	return err == nil && matcher.MatchString(filePath)
+}
+
+// Convert glob pattern to regular expression that matches the whole path
+func globToRegexp(pattern string) string {
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end == -1 {
+				expr.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, "\\", "\\\\") + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	expr.WriteString("$")
+	return expr.String()
+}
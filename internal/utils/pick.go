@@ -0,0 +1,40 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"fmt"
+)
+
+// Apply changes made by commit (compared to its parent) to index and working tree with three-way merge
+// With reverse the opposite changes are applied (revert), conflicted paths are left with merge stages and conflict markers
+func ApplyCommitChanges(commit Commit, reverse bool) (MergeResult, error) {
+	if len(commit.Parents) > 1 {
+		return MergeResult{}, fmt.Errorf("commit %s is a merge, which can't be applied", commit.Hash)
+	}
+
+	parent := ""
+	if len(commit.Parents) == 1 {
+		parent = commit.Parents[0]
+	}
+
+	baseEntries, theirsEntries := GetCommitFiles(parent), GetCommitFiles(commit.Hash)
+	theirsLabel := fmt.Sprintf("%s (%s)", commit.Hash[:7], commit.Subject())
+	if reverse {
+		baseEntries, theirsEntries = theirsEntries, baseEntries
+		theirsLabel = "parent of " + theirsLabel
+	}
+
+	indexEntries, err := ReadIndexFile()
+	if err != nil {
+		return MergeResult{}, err
+	}
+
+	result, err := MergeTrees(baseEntries, indexEntries, theirsEntries, "HEAD", theirsLabel)
+	if err != nil {
+		return MergeResult{}, err
+	}
+
+	return result, ApplyMergeResult(result)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Mode of tree entry that points to commit of another repository (submodule), the object is never stored here
+const submoduleMode = "160000"
+
+// Walk objects reachable from start objects and add them to result, objects already in result or in exclude are not walked again
+func collectReachable(start []string, exclude map[string]bool, result map[string]bool) error {
+	stack := append([]string{}, start...)
+
+	for len(stack) > 0 {
+		hashValue := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if result[hashValue] || exclude[hashValue] {
+			continue
+		}
+
+		objType, content, err := ReadObject(hashValue)
+		if err != nil {
+			return err
+		}
+		result[hashValue] = true
+
+		switch objType {
+		case CommitObject:
+			commit, err := ParseCommit(content)
+			if err != nil {
+				return fmt.Errorf("bad commit '%s': %v", hashValue, err)
+			}
+			stack = append(stack, commit.Tree)
+			stack = append(stack, commit.Parents...)
+		case TreeObject:
+			entries, err := ParseTree(content)
+			if err != nil {
+				return fmt.Errorf("bad tree '%s': %v", hashValue, err)
+			}
+			for _, entry := range entries {
+				switch {
+				case entry.Mode == submoduleMode || exclude[entry.Hash]:
+				case entry.IsTree():
+					stack = append(stack, entry.Hash)
+				default:
+					// Blob has nothing to walk, so it doesn't need to be read
+					result[entry.Hash] = true
+				}
+			}
+		case TagObject:
+			target, _, _ := strings.Cut(string(content), "\n")
+			if !strings.HasPrefix(target, "object ") {
+				return fmt.Errorf("malformed tag object '%s'", hashValue)
+			}
+			stack = append(stack, strings.TrimPrefix(target, "object "))
+		}
+	}
+
+	return nil
+}
+
+// Get objects another repository needs to have wanted objects when it already has the objects reachable from haves
+// Haves that are not stored here are ignored
+func MissingObjects(wants []string, haves []string) ([]string, error) {
+	excluded := make(map[string]bool)
+	for _, have := range haves {
+		if !HasObject(have) {
+			continue
+		}
+		err := collectReachable([]string{have}, nil, excluded)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	included := make(map[string]bool)
+	err := collectReachable(wants, excluded, included)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(included))
+	for hashValue := range included {
+		hashes = append(hashes, hashValue)
+	}
+	sort.Strings(hashes)
+
+	return hashes, nil
+}
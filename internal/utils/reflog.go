@@ -0,0 +1,224 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hash value written in reflog when ref doesn't exist before or after the update
+const reflogZeroHash = "0000000000000000000000000000000000000000"
+
+// One update of reference recorded in logs folder ("<old> <new> <identity> <time>\t<message>")
+type ReflogEntry struct {
+	OldHash   string
+	NewHash   string
+	Committer Signature
+	Message   string
+}
+
+// Format reflog entry as line of reflog file
+func (entry ReflogEntry) String() string {
+	oldHash, newHash := entry.OldHash, entry.NewHash
+	if oldHash == "" {
+		oldHash = reflogZeroHash
+	}
+	if newHash == "" {
+		newHash = reflogZeroHash
+	}
+	return fmt.Sprintf("%s %s %s\t%s\n", oldHash, newHash, entry.Committer, entry.Message)
+}
+
+// Parse one line of reflog file
+func parseReflogEntry(line string) (ReflogEntry, error) {
+	header, message, _ := strings.Cut(line, "\t")
+	if len(header) < 82 || header[40] != ' ' || header[81] != ' ' {
+		return ReflogEntry{}, fmt.Errorf("malformed reflog entry '%s'", line)
+	}
+
+	committer, err := ParseSignature(header[82:])
+	if err != nil {
+		return ReflogEntry{}, err
+	}
+
+	entry := ReflogEntry{OldHash: header[:40], NewHash: header[41:81], Committer: committer, Message: message}
+	if entry.OldHash == reflogZeroHash {
+		entry.OldHash = ""
+	}
+	if entry.NewHash == reflogZeroHash {
+		entry.NewHash = ""
+	}
+	return entry, nil
+}
+
+// Get the path of reflog file of reference (e.g .git-go/logs/refs/heads/main)
+func reflogPath(name string) string {
+	return GitPath("logs", filepath.FromSlash(name))
+}
+
+// Check updates of reference are recorded, HEAD, branches, remote-tracking branches and stash have reflog (same as Git)
+func shouldLogRef(name string) bool {
+	return name == "HEAD" || name == StashRef || strings.HasPrefix(name, "refs/heads/") || strings.HasPrefix(name, "refs/remotes/")
+}
+
+// Append entry to reflog of reference, identity comes from user config (or "unknown" when it is not set)
+func AppendReflog(name string, oldHash string, newHash string, message string) error {
+	committer, err := GetUserSignature()
+	if err != nil {
+		committer = Signature{Name: "unknown", Email: "unknown", When: time.Now()}
+	}
+
+	// Message is one line, so it can't break reflog format
+	message = strings.Join(strings.Fields(message), " ")
+	entry := ReflogEntry{OldHash: oldHash, NewHash: newHash, Committer: committer, Message: message}
+
+	path := reflogPath(name)
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(entry.String())
+	return err
+}
+
+// Read every entry of reflog, oldest first (empty when reference has no reflog)
+func ReadReflog(name string) ([]ReflogEntry, error) {
+	content, err := os.ReadFile(reflogPath(name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ReflogEntry
+	for _, line := range strings.Split(string(content), "\n") {
+		if line == "" {
+			continue
+		}
+		entry, err := parseReflogEntry(line)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Replace every entry of reflog (used to expire or delete entries)
+func WriteReflog(name string, entries []ReflogEntry) error {
+	var sb strings.Builder
+	for _, entry := range entries {
+		sb.WriteString(entry.String())
+	}
+	return writeFileAtomic(reflogPath(name), []byte(sb.String()), 0644)
+}
+
+// Remove reflog of reference, missing reflog is ignored
+func DeleteReflog(name string) error {
+	err := os.Remove(reflogPath(name))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Check reference has reflog file
+func HasReflog(name string) bool {
+	info, err := os.Stat(reflogPath(name))
+	return err == nil && !info.IsDir()
+}
+
+// List names of every reference that has reflog (e.g HEAD, refs/heads/main)
+func ListReflogs() []string {
+	var names []string
+	logsDir := GitPath("logs")
+
+	filepath.WalkDir(logsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(logsDir, path)
+		if err == nil {
+			names = append(names, filepath.ToSlash(relPath))
+		}
+		return nil
+	})
+
+	return names
+}
+
+// Get full name of reference for reflog lookup, empty name means current branch (or HEAD when it is detached)
+func ReflogRefName(name string) (string, error) {
+	switch {
+	case name == "":
+		if branch := GerCurrentBranch(); branch != "" {
+			return "refs/heads/" + branch, nil
+		}
+		return "HEAD", nil
+	case name == "HEAD" || strings.HasPrefix(name, "refs/"):
+		return name, nil
+	case BranchExists(name):
+		return "refs/heads/" + name, nil
+	case ReadRef("refs/remotes/"+name) != "":
+		return "refs/remotes/" + name, nil
+	case HasReflog("refs/" + name):
+		return "refs/" + name, nil // e.g stash
+	}
+	return "", fmt.Errorf("unknown reference '%s'", name)
+}
+
+// Split "<ref>@{<n>}" into reference name and entry number, false is returned for other names
+func ParseReflogSelector(name string) (string, int, bool) {
+	ref, rest, ok := strings.Cut(name, "@{")
+	if !ok || !strings.HasSuffix(rest, "}") {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(strings.TrimSuffix(rest, "}"))
+	if err != nil || number < 0 {
+		return "", 0, false
+	}
+	return ref, number, true
+}
+
+// Resolve "<ref>@{<n>}" to the value reference had n updates ago
+func resolveReflogSelector(name string) (string, error) {
+	ref, number, ok := ParseReflogSelector(name)
+	if !ok {
+		return "", fmt.Errorf("unknown revision '%s'", name)
+	}
+
+	fullName, err := ReflogRefName(ref)
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := ReadReflog(fullName)
+	if err != nil {
+		return "", err
+	}
+	if number >= len(entries) {
+		return "", fmt.Errorf("log for '%s' only has %d entries", ref, len(entries))
+	}
+
+	hashValue := entries[len(entries)-1-number].NewHash
+	if hashValue == "" {
+		return "", fmt.Errorf("reference '%s' was deleted at that point", name)
+	}
+	return hashValue, nil
+}
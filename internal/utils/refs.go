@@ -0,0 +1,434 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Get the commit hash value that branch is pointing to (empty string for unknown or unborn branch)
+func GetBranchCommit(branch string) string {
+	content, err := os.ReadFile(GitPath("refs", "heads", branch))
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
+
+// Check branch reference file is exist under refs/heads, invalid name (e.g "../../HEAD") is never a branch
+func BranchExists(branch string) bool {
+	if ValidateRefName("refs/heads/"+branch) != nil {
+		return false
+	}
+	info, err := os.Stat(GitPath("refs", "heads", branch))
+	return err == nil && !info.IsDir()
+}
+
+// Create branch reference file pointing to the given commit, message is recorded in branch reflog
+func CreateBranch(branch string, commitHash string, message string) error {
+	return UpdateRef("refs/heads/"+branch, commitHash, message)
+}
+
+// Point HEAD to the given branch (e.g ref: refs/heads/dev), HEAD reflog records the move when message is given
+func SetHeadToBranch(branch string, message string) error {
+	// HEAD doesn't exist yet when repository is initialized (message is empty then)
+	oldHash := ""
+	if message != "" {
+		oldHash = GetCurrentCommit()
+	}
+
+	err := os.WriteFile(GitPath("HEAD"), []byte(fmt.Sprintf("ref: refs/heads/%s\n", branch)), 0644)
+	if err != nil {
+		return err
+	}
+
+	if newHash := GetBranchCommit(branch); message != "" && newHash != "" {
+		return AppendReflog("HEAD", oldHash, newHash, message)
+	}
+	return nil
+}
+
+// Point HEAD directly to the given commit (detached HEAD), HEAD reflog records the move when message is given
+func SetDetachedHead(commitHash string, message string) error {
+	oldHash := ""
+	if message != "" {
+		oldHash = GetCurrentCommit()
+	}
+
+	err := os.WriteFile(GitPath("HEAD"), []byte(commitHash+"\n"), 0644)
+	if err != nil {
+		return err
+	}
+
+	if message != "" {
+		return AppendReflog("HEAD", oldHash, commitHash, message)
+	}
+	return nil
+}
+
+// Get the object hash value of reference by its full name (e.g refs/remotes/origin/main), empty string for unknown reference
+func ReadRef(name string) string {
+	content, err := os.ReadFile(GitPath(filepath.FromSlash(name)))
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
+
+// Write reference file by its full name, reflog is not updated
+func writeRef(name string, hashValue string) error {
+	refPath := GitPath(filepath.FromSlash(name))
+
+	err := os.MkdirAll(filepath.Dir(refPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(refPath, []byte(hashValue+"\n"), 0644)
+}
+
+// Create or update reference by its full name (or HEAD) and record the update with message in reflog
+// HEAD pointing to branch updates the branch, and update of the branch HEAD points to is recorded in HEAD reflog too (same as Git)
+func UpdateRef(name string, hashValue string, message string) error {
+	target := name
+	currentBranch := GerCurrentBranch()
+	if name == "HEAD" && currentBranch != "" {
+		target = "refs/heads/" + currentBranch
+	}
+
+	oldHash := ReadRef(target)
+	err := writeRef(target, hashValue)
+	if err != nil {
+		return err
+	}
+
+	if shouldLogRef(target) {
+		err = AppendReflog(target, oldHash, hashValue, message)
+		if err != nil {
+			return err
+		}
+	}
+	if target != "HEAD" && currentBranch != "" && target == "refs/heads/"+currentBranch {
+		return AppendReflog("HEAD", oldHash, hashValue, message)
+	}
+
+	return nil
+}
+
+// Remove reference by its full name together with its reflog
+func DeleteRef(name string) error {
+	err := os.Remove(GitPath(filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	return DeleteReflog(name)
+}
+
+// Check reference name follows Git rules (no "..", spaces, control or special characters, etc)
+func ValidateRefName(name string) error {
+	invalid := fmt.Errorf("'%s' is not a valid reference name", name)
+
+	if name == "" || name == "@" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, "/") ||
+		strings.HasSuffix(name, "/") || strings.HasSuffix(name, ".") || strings.HasSuffix(name, ".lock") ||
+		strings.Contains(name, "..") || strings.Contains(name, "//") || strings.Contains(name, "@{") {
+		return invalid
+	}
+
+	for _, c := range name {
+		if c < 0x20 || c == 0x7f || strings.ContainsRune(" ~^:?*[\\", c) {
+			return invalid
+		}
+	}
+
+	for _, component := range strings.Split(name, "/") {
+		if strings.HasPrefix(component, ".") {
+			return invalid
+		}
+	}
+
+	return nil
+}
+
+// Get the object hash value that tag is pointing to (empty string for unknown tag)
+func GetTagTarget(tag string) string {
+	content, err := os.ReadFile(GitPath("refs", "tags", tag))
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
+
+// Check tag reference file is exist under refs/tags
+func TagExists(tag string) bool {
+	info, err := os.Stat(GitPath("refs", "tags", tag))
+	return err == nil && !info.IsDir()
+}
+
+// Create or replace tag reference file pointing to the given object
+func CreateTag(tag string, hashValue string) error {
+	tagPath := GitPath("refs", "tags", tag)
+
+	// Tag name can contain "/" (e.g release/v1.0), so make sure parent folders exist
+	err := os.MkdirAll(filepath.Dir(tagPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(tagPath, []byte(hashValue+"\n"), 0644)
+}
+
+// Remove tag reference file together with parent folders that become empty
+func DeleteTag(tag string) error {
+	err := os.Remove(GitPath("refs", "tags", tag))
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(GitPath("refs", "tags", tag))
+	for dir != GitPath("refs", "tags") {
+		if os.Remove(dir) != nil {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	return nil
+}
+
+// List names of every reference file under refs folder (e.g ListRefs("tags") returns v1.0, release/v2.0)
+func ListRefs(kind string) []string {
+	return listRefFiles(GitPath("refs", kind))
+}
+
+// List full names of every reference (e.g refs/heads/main, refs/tags/v1.0)
+func ListAllRefs() []string {
+	var names []string
+	for _, name := range listRefFiles(GitPath("refs")) {
+		names = append(names, "refs/"+name)
+	}
+	return names
+}
+
+// List every reference file under the folder by its name relative to the folder
+func listRefFiles(root string) []string {
+	var names []string
+
+	filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		name, err := filepath.Rel(root, path)
+		if err == nil {
+			names = append(names, filepath.ToSlash(name))
+		}
+		return nil
+	})
+
+	sort.Strings(names)
+	return names
+}
+
+// Follow annotated tags until object that is not a tag is reached
+func PeelObject(hashValue string) (string, string, error) {
+	for {
+		objType, content, err := ReadObject(hashValue)
+		if err != nil {
+			return "", "", err
+		}
+		if objType != TagObject {
+			return hashValue, objType, nil
+		}
+
+		// First line of tag object is "object <hash>"
+		firstLine, _, _ := strings.Cut(string(content), "\n")
+		if !strings.HasPrefix(firstLine, "object ") {
+			return "", "", fmt.Errorf("malformed tag object '%s'", hashValue)
+		}
+		hashValue = strings.TrimPrefix(firstLine, "object ")
+	}
+}
+
+// Check the value is a full or abbreviated hexadecimal hash value
+func isHexHash(value string) bool {
+	if len(value) < 4 || len(value) > 40 {
+		return false
+	}
+
+	for _, c := range value {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Find all stored objects (loose or packed) whose hash value start with the given prefix
+func FindObjectsByPrefix(prefix string) []string {
+	var matches []string
+	seen := make(map[string]bool)
+
+	dirEntries, _ := os.ReadDir(GitPath("objects", prefix[:2]))
+	for _, entry := range dirEntries {
+		hashValue := prefix[:2] + entry.Name()
+		if strings.HasPrefix(hashValue, prefix) && !seen[hashValue] {
+			seen[hashValue] = true
+			matches = append(matches, hashValue)
+		}
+	}
+
+	for _, index := range Packs() {
+		for _, hashValue := range index.FindPrefix(prefix) {
+			if !seen[hashValue] {
+				seen[hashValue] = true
+				matches = append(matches, hashValue)
+			}
+		}
+	}
+
+	return matches
+}
+
+// Resolve object name (HEAD, branch name, tag name, full or abbreviated hash value or "<revision>:<path>") to hash value of any object type
+func ResolveObjectName(name string) (string, error) {
+	// "<revision>:<path>" points to file or folder inside commit snapshot (e.g HEAD:README.md)
+	if revision, path, ok := strings.Cut(name, ":"); ok {
+		commitHash, err := ResolveRevision(revision)
+		if err != nil {
+			return "", err
+		}
+		return LookupTreePath(GetCommitTreeHash(commitHash), path)
+	}
+
+	// "~<n>" and "^<n>" suffixes walk to parent commits (e.g HEAD~2, main^2), ref names never have these characters
+	if i := strings.IndexAny(name, "~^"); i > 0 {
+		return resolveParentSuffix(name[:i], name[i:])
+	}
+
+	// "<ref>@{<n>}" is value of reference n updates ago (e.g HEAD@{1}), found in reflog
+	if strings.Contains(name, "@{") {
+		return resolveReflogSelector(name)
+	}
+
+	if name == "HEAD" {
+		commitHash := GetCurrentCommit()
+		if commitHash == "" {
+			return "", fmt.Errorf("HEAD does not point to any commit yet")
+		}
+		return commitHash, nil
+	}
+
+	if BranchExists(name) {
+		commitHash := GetBranchCommit(name)
+		if commitHash == "" {
+			return "", fmt.Errorf("branch '%s' does not point to any commit", name)
+		}
+		return commitHash, nil
+	}
+
+	if TagExists(name) {
+		return GetTagTarget(name), nil
+	}
+
+	// Full reference name (e.g refs/heads/dev) or remote-tracking branch (e.g origin/main)
+	if ValidateRefName(name) == nil {
+		for _, candidate := range []string{name, "refs/" + name, "refs/remotes/" + name} {
+			if strings.HasPrefix(candidate, "refs/") {
+				if hashValue := ReadRef(candidate); hashValue != "" {
+					return hashValue, nil
+				}
+			}
+		}
+	}
+
+	if isHexHash(name) {
+		matches := FindObjectsByPrefix(name)
+		if len(matches) > 1 {
+			return "", fmt.Errorf("short hash '%s' is ambiguous", name)
+		}
+		if len(matches) == 1 {
+			return matches[0], nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown revision '%s'", name)
+}
+
+// Walk parents of base revision by suffix, "~<n>" follows first parent n times and "^<n>" takes n-th parent (number is 1 when omitted)
+func resolveParentSuffix(base string, suffix string) (string, error) {
+	commitHash, err := ResolveRevision(base)
+	if err != nil {
+		return "", err
+	}
+
+	for suffix != "" {
+		operator := suffix[0]
+		digits := strings.IndexAny(suffix[1:], "~^")
+		if digits == -1 {
+			digits = len(suffix) - 1
+		}
+		number := 1
+		if digits > 0 {
+			number, err = strconv.Atoi(suffix[1 : 1+digits])
+			if err != nil || number < 0 {
+				return "", fmt.Errorf("unknown revision '%s'", base+suffix)
+			}
+		}
+		suffix = suffix[1+digits:]
+
+		if operator == '^' {
+			if number == 0 {
+				continue // "^0" is the commit itself
+			}
+			commit, err := ReadCommit(commitHash)
+			if err != nil {
+				return "", err
+			}
+			if number > len(commit.Parents) {
+				return "", fmt.Errorf("commit '%s' has no parent %d", commitHash[:7], number)
+			}
+			commitHash = commit.Parents[number-1]
+			continue
+		}
+
+		for ; number > 0; number-- {
+			commit, err := ReadCommit(commitHash)
+			if err != nil {
+				return "", err
+			}
+			if len(commit.Parents) == 0 {
+				return "", fmt.Errorf("commit '%s' has no parent", commitHash[:7])
+			}
+			commitHash = commit.Parents[0]
+		}
+	}
+
+	return commitHash, nil
+}
+
+// Resolve revision (HEAD, branch name, tag name, full or abbreviated commit hash, with "~<n>" or "^<n>" suffix) to commit hash value
+func ResolveRevision(revision string) (string, error) {
+	hashValue, err := ResolveObjectName(revision)
+	if err != nil {
+		return "", err
+	}
+
+	// Annotated tag is resolved to the commit it is pointing to
+	hashValue, objType, err := PeelObject(hashValue)
+	if err != nil {
+		return "", err
+	}
+	if objType != CommitObject {
+		return "", fmt.Errorf("object '%s' is a %s, not a commit", hashValue, objType)
+	}
+
+	return hashValue, nil
+}
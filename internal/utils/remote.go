@@ -0,0 +1,112 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mapping between remote refs and local refs (e.g +refs/heads/*:refs/remotes/origin/*)
+type RefSpec struct {
+	Force bool   // Update destination even when it is not fast-forward ("+" prefix)
+	Src   string // Source ref or pattern with one "*"
+	Dst   string // Destination ref or pattern with one "*"
+}
+
+// Parse refspec in "[+]<src>:<dst>" form, "<src>" alone is same as "<src>:<src>"
+func ParseRefSpec(spec string) (RefSpec, error) {
+	var refSpec RefSpec
+	if strings.HasPrefix(spec, "+") {
+		refSpec.Force = true
+		spec = spec[1:]
+	}
+
+	src, dst, ok := strings.Cut(spec, ":")
+	if !ok {
+		dst = src
+	}
+	if src == "" || strings.Count(src, "*") > 1 || strings.Count(src, "*") != strings.Count(dst, "*") {
+		return RefSpec{}, fmt.Errorf("invalid refspec '%s'", spec)
+	}
+	refSpec.Src, refSpec.Dst = src, dst
+
+	return refSpec, nil
+}
+
+// Format refspec back to "[+]<src>:<dst>"
+func (refSpec RefSpec) String() string {
+	prefix := ""
+	if refSpec.Force {
+		prefix = "+"
+	}
+	return prefix + refSpec.Src + ":" + refSpec.Dst
+}
+
+// Map source ref to destination ref, false is returned when ref doesn't match source
+func (refSpec RefSpec) MapSource(ref string) (string, bool) {
+	before, after, isPattern := strings.Cut(refSpec.Src, "*")
+	if !isPattern {
+		return refSpec.Dst, ref == refSpec.Src
+	}
+
+	if !strings.HasPrefix(ref, before) || !strings.HasSuffix(ref, after) || len(ref) < len(before)+len(after) {
+		return "", false
+	}
+	matched := ref[len(before) : len(ref)-len(after)]
+	return strings.Replace(refSpec.Dst, "*", matched, 1), true
+}
+
+// Get URL of remote from config
+func GetRemoteURL(remote string) (string, bool) {
+	return GetConfigValue("remote." + remote + ".url")
+}
+
+// Get fetch refspecs of remote from config, default refspec is used when remote has none
+func GetRemoteFetchSpecs(remote string) ([]RefSpec, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	specs := config.GetAll("remote." + remote + ".fetch")
+	if len(specs) == 0 {
+		specs = []string{DefaultFetchSpec(remote)}
+	}
+
+	var refSpecs []RefSpec
+	for _, spec := range specs {
+		refSpec, err := ParseRefSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		refSpecs = append(refSpecs, refSpec)
+	}
+
+	return refSpecs, nil
+}
+
+// Get default fetch refspec that maps remote branches to remote-tracking branches
+func DefaultFetchSpec(remote string) string {
+	return fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", remote)
+}
+
+// Save remote URL with default fetch refspec in repository config
+func AddRemote(remote string, url string) error {
+	config, err := LoadConfigFile(RepoConfigPath())
+	if err != nil {
+		return err
+	}
+
+	err = config.Set("remote."+remote+".url", url)
+	if err != nil {
+		return err
+	}
+	err = config.Set("remote."+remote+".fetch", DefaultFetchSpec(remote))
+	if err != nil {
+		return err
+	}
+
+	return config.Save(RepoConfigPath())
+}
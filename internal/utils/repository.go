@@ -0,0 +1,198 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Name of repository folder created by init
+const GitDirName = ".git-go"
+
+var (
+	gitDir     = GitDirName // Repository folder, relative to working tree root when it is inside of it
+	pathPrefix = ""         // Folder where command was started, relative to working tree root
+)
+
+// Use the given folder as repository folder without discovery (e.g for init)
+func SetGitDir(dir string) {
+	gitDir = filepath.Clean(dir)
+}
+
+// Get the path of repository folder
+func GitDir() string {
+	return gitDir
+}
+
+// Get the path of file or folder inside repository folder (e.g GitPath("refs", "heads") for .git-go/refs/heads)
+func GitPath(elem ...string) string {
+	return filepath.Join(append([]string{gitDir}, elem...)...)
+}
+
+// Create repository folder with its sub-folders and files, HEAD points to the given branch
+func InitRepository(defaultBranch string) error {
+	for _, dir := range []string{GitDir(), GitPath("objects"), GitPath("refs", "heads"), GitPath("refs", "tags")} {
+		err := os.MkdirAll(dir, 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, file := range []string{"config", "index"} {
+		err := os.WriteFile(GitPath(file), nil, 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return SetHeadToBranch(defaultBranch, "")
+}
+
+// Find repository folder by searching from current folder up to file system root
+func DiscoverGitDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		candidate := filepath.Join(dir, GitDirName)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("not a git-go repository (or any of the parent directories): %s", GitDirName)
+		}
+		dir = parent
+	}
+}
+
+// Find repository and working tree, then move into working tree root so all paths inside repository are relative to it
+// gitDirFlag and workTreeFlag override discovery, GIT_GO_DIR and GIT_GO_WORK_TREE environment variables are used when they are empty
+func SetupRepository(gitDirFlag string, workTreeFlag string) error {
+	if gitDirFlag == "" {
+		gitDirFlag = os.Getenv("GIT_GO_DIR")
+	}
+	if workTreeFlag == "" {
+		workTreeFlag = os.Getenv("GIT_GO_WORK_TREE")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	var absGitDir, absWorkTree string
+	if gitDirFlag != "" {
+		absGitDir, err = filepath.Abs(gitDirFlag)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(absGitDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("not a git-go repository: '%s'", gitDirFlag)
+		}
+		// Explicit repository folder without working tree use current folder as working tree (same as Git)
+		absWorkTree = cwd
+	} else {
+		absGitDir, err = DiscoverGitDir()
+		if err != nil {
+			return err
+		}
+		absWorkTree = filepath.Dir(absGitDir)
+	}
+
+	if workTreeFlag != "" {
+		absWorkTree, err = filepath.Abs(workTreeFlag)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Remember where command was started to resolve path arguments later
+	prefix, err := filepath.Rel(absWorkTree, cwd)
+	if err != nil || prefix == ".." || strings.HasPrefix(prefix, ".."+string(filepath.Separator)) {
+		prefix = ""
+	}
+	if prefix == "." {
+		prefix = ""
+	}
+	pathPrefix = prefix
+
+	err = os.Chdir(absWorkTree)
+	if err != nil {
+		return err
+	}
+
+	// Keep repository path short when it is inside working tree (e.g .git-go)
+	gitDir = absGitDir
+	if relGitDir, err := filepath.Rel(absWorkTree, absGitDir); err == nil && !strings.HasPrefix(relGitDir, "..") {
+		gitDir = relGitDir
+	}
+
+	return nil
+}
+
+// Convert path argument given by user (relative to folder where command was started) to path relative to working tree root
+func ToRepoPath(userPath string) (string, error) {
+	path := userPath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(pathPrefix, path)
+	} else {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		path, err = filepath.Rel(cwd, path)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	path = filepath.Clean(path)
+	if path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("'%s' is outside repository", userPath)
+	}
+
+	return filepath.ToSlash(path), nil
+}
+
+// Check the folder (relative to working tree root) is the repository folder, which must never be tracked
+func IsGitDirPath(path string) bool {
+	return filepath.Base(path) == GitDirName || filepath.Clean(path) == filepath.Clean(gitDir)
+}
+
+// Read state file inside repository folder (e.g MERGE_HEAD), false is returned when it doesn't exist
+func ReadStateFile(name string) (string, bool) {
+	content, err := os.ReadFile(GitPath(name))
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
+}
+
+// Write state file inside repository folder
+func WriteStateFile(name string, content string) error {
+	err := os.MkdirAll(filepath.Dir(GitPath(name)), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(GitPath(name), []byte(content), 0644)
+}
+
+// Remove state files inside repository folder, missing files are ignored
+func RemoveStateFiles(names ...string) {
+	for _, name := range names {
+		os.Remove(GitPath(name))
+	}
+}
+
+// Remove state folder inside repository folder together with its files (e.g rebase-merge)
+func RemoveStateDir(name string) error {
+	return os.RemoveAll(GitPath(name))
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kei-K23/git-go/pkg/object"
+)
+
+// Identity with timestamp stored in author, committer and tagger lines, it is the signature of pkg/object
+type Signature = object.Signature
+
+// Parse signature from Git format, older RFC3339 timestamp written by git-go is accepted too
+func ParseSignature(value string) (Signature, error) {
+	if signature, err := object.ParseSignature(value); err == nil {
+		return signature, nil
+	}
+	return parseLegacySignature(value)
+}
+
+// Parse signature leniently, older git-go versions wrote RFC3339 timestamp (e.g "author <author@gmail.com> 2024-09-24T15:54:09+06:30")
+func parseLegacySignature(value string) (Signature, error) {
+	emailStart := strings.Index(value, "<")
+	emailEnd := strings.LastIndex(value, ">")
+	if emailStart == -1 || emailEnd < emailStart {
+		return Signature{}, errors.New("signature is missing email")
+	}
+
+	signature := Signature{
+		Name:  strings.TrimSpace(value[:emailStart]),
+		Email: value[emailStart+1 : emailEnd],
+	}
+
+	dateValue := strings.TrimSpace(value[emailEnd+1:])
+	if when, err := time.Parse(time.RFC3339, dateValue); err == nil {
+		signature.When = when
+		return signature, nil
+	}
+
+	parts := strings.Fields(dateValue)
+	if len(parts) != 2 {
+		return Signature{}, fmt.Errorf("invalid signature date '%s'", dateValue)
+	}
+
+	timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return Signature{}, fmt.Errorf("invalid signature timestamp '%s'", parts[0])
+	}
+
+	// Timezone is written as +hhmm or -hhmm
+	zone := parts[1]
+	if len(zone) != 5 || (zone[0] != '+' && zone[0] != '-') {
+		return Signature{}, fmt.Errorf("invalid signature timezone '%s'", zone)
+	}
+	hours, hoursErr := strconv.Atoi(zone[1:3])
+	minutes, minutesErr := strconv.Atoi(zone[3:5])
+	if hoursErr != nil || minutesErr != nil {
+		return Signature{}, fmt.Errorf("invalid signature timezone '%s'", zone)
+	}
+	offset := hours*3600 + minutes*60
+	if zone[0] == '-' {
+		offset = -offset
+	}
+	signature.When = time.Unix(timestamp, 0).In(time.FixedZone("", offset))
+
+	return signature, nil
+}
+
+// Create signature of current user from user.name and user.email config at current time
+func GetUserSignature() (Signature, error) {
+	name, hasName := GetConfigValue("user.name")
+	email, hasEmail := GetConfigValue("user.email")
+	if !hasName || !hasEmail || strings.TrimSpace(name) == "" || strings.TrimSpace(email) == "" {
+		return Signature{}, errors.New(`Author identity unknown
+
+*** Please tell me who you are.
+
+Run
+
+  git-go config --global user.email "you@example.com"
+  git-go config --global user.name "Your Name"
+
+to set your account's default identity.
+Omit --global to set the identity only in this repository.`)
+	}
+
+	return Signature{Name: name, Email: email, When: time.Now()}, nil
+}
+
+// Format signature time for human readable output (e.g Tue Sep 24 15:54:09 2024 +0630)
+func FormatSignatureTime(when time.Time) string {
+	return when.Format("Mon Jan 2 15:04:05 2006 -0700")
+}
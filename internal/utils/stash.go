@@ -0,0 +1,131 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Reference of the latest stash entry, older entries are kept in its reflog (stash@{n})
+const StashRef = "refs/stash"
+
+// Store working tree version of tracked files as blobs, files removed from working tree are left out
+func WorkingTreeEntries(entries []IndexEntry) ([]IndexEntry, error) {
+	var workingEntries []IndexEntry
+	for _, entry := range entries {
+		if entry.Stage() != 0 {
+			continue
+		}
+
+		fileInfo, err := os.Lstat(entry.Path)
+		if err != nil {
+			continue
+		}
+		isModified, err := IsEntryModified(entry, fileInfo)
+		if err != nil {
+			return nil, err
+		}
+		if !isModified {
+			workingEntries = append(workingEntries, entry)
+			continue
+		}
+
+		fileEntry, err := StoreWorkingFile(entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		workingEntries = append(workingEntries, fileEntry)
+	}
+
+	return workingEntries, nil
+}
+
+// Store content of working tree file as blob and get its entry (stat data is not cached)
+func StoreWorkingFile(path string) (IndexEntry, error) {
+	fileInfo, err := os.Lstat(path)
+	if err != nil {
+		return IndexEntry{}, err
+	}
+
+	content, err := ReadWorkingFile(path)
+	if err != nil {
+		return IndexEntry{}, err
+	}
+	hashValue, err := WriteObject(BlobObject, content)
+	if err != nil {
+		return IndexEntry{}, err
+	}
+
+	return IndexEntry{Mode: FileModeOf(fileInfo), Hash: hashValue, Path: path}, nil
+}
+
+// Get every stash entry, newest first (stash@{0} is the first one)
+func ListStashes() ([]ReflogEntry, error) {
+	entries, err := ReadReflog(StashRef)
+	if err != nil {
+		return nil, err
+	}
+
+	stashes := make([]ReflogEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		stashes = append(stashes, entries[i])
+	}
+	return stashes, nil
+}
+
+// Resolve stash name to stash commit, "" is the latest stash and "<n>" is the same as stash@{n}
+func ResolveStash(name string) (string, error) {
+	if name == "" {
+		name = "stash@{0}"
+	} else if _, err := strconv.Atoi(name); err == nil {
+		name = "stash@{" + name + "}"
+	}
+
+	if ref, number, ok := ParseReflogSelector(name); ok && (ref == "stash" || ref == StashRef) {
+		stashes, err := ListStashes()
+		if err != nil {
+			return "", err
+		}
+		if len(stashes) == 0 {
+			return "", errors.New("No stash entries found.")
+		}
+		if number >= len(stashes) {
+			return "", fmt.Errorf("stash@{%d} is not a valid reference", number)
+		}
+		return stashes[number].NewHash, nil
+	}
+
+	return ResolveRevision(name)
+}
+
+// Remove n-th stash entry, refs/stash moves to next entry (or is removed when no entry is left)
+func DropStash(number int) (ReflogEntry, error) {
+	entries, err := ReadReflog(StashRef)
+	if err != nil {
+		return ReflogEntry{}, err
+	}
+	if len(entries) == 0 {
+		return ReflogEntry{}, errors.New("No stash entries found.")
+	}
+	if number < 0 || number >= len(entries) {
+		return ReflogEntry{}, fmt.Errorf("stash@{%d} is not a valid reference", number)
+	}
+
+	index := len(entries) - 1 - number
+	dropped := entries[index]
+	entries = append(entries[:index], entries[index+1:]...)
+
+	if len(entries) == 0 {
+		return dropped, DeleteRef(StashRef)
+	}
+
+	err = WriteReflog(StashRef, entries)
+	if err != nil {
+		return ReflogEntry{}, err
+	}
+	return dropped, writeRef(StashRef, entries[len(entries)-1].NewHash)
+}
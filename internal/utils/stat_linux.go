@@ -0,0 +1,28 @@
+//go:build linux
+
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"os"
+	"syscall"
+)
+
+// Fill ctime, device, inode and owner of index entry from Linux stat data
+func fillSystemStat(entry *IndexEntry, fileInfo os.FileInfo) {
+	stat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		entry.CTimeSeconds = entry.MTimeSeconds
+		entry.CTimeNanoseconds = entry.MTimeNanoseconds
+		return
+	}
+
+	entry.CTimeSeconds = uint32(stat.Ctim.Sec)
+	entry.CTimeNanoseconds = uint32(stat.Ctim.Nsec)
+	entry.Dev = uint32(stat.Dev)
+	entry.Ino = uint32(stat.Ino)
+	entry.UID = stat.Uid
+	entry.GID = stat.Gid
+}
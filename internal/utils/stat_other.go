@@ -0,0 +1,14 @@
+//go:build !linux
+
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import "os"
+
+// Stat data beyond mtime and size is not portable, so ctime falls back to mtime
+func fillSystemStat(entry *IndexEntry, fileInfo os.FileInfo) {
+	entry.CTimeSeconds = entry.MTimeSeconds
+	entry.CTimeNanoseconds = entry.MTimeNanoseconds
+}
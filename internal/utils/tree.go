@@ -0,0 +1,223 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Kei-K23/git-go/pkg/object"
+)
+
+// Mode of tree entry that points to another tree object (sub directory)
+const TreeMode = "40000"
+
+// Single entry of tree object, Name is file or folder name without parent path
+type TreeEntry struct {
+	Mode string
+	Name string
+	Hash string
+}
+
+// Check tree entry is pointing to sub tree
+func (entry TreeEntry) IsTree() bool {
+	return entry.Mode == TreeMode
+}
+
+// Get the tree hash value that commit object is pointing to
+func GetCommitTreeHash(commitHash string) string {
+	commitContent := string(ReadCommitObject(commitHash))
+
+	for _, line := range strings.Split(commitContent, "\n") {
+		// Commit headers end at the first empty line
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "tree ") {
+			return strings.TrimPrefix(line, "tree ")
+		}
+	}
+
+	return ""
+}
+
+// Encode tree entries to tree object content with pkg/object (e.g "100644 README.md\x00<20 bytes hash>" for each entry)
+func EncodeTree(entries []TreeEntry) ([]byte, error) {
+	var tree object.Tree
+	for _, entry := range entries {
+		mode, err := object.ParseFileMode(entry.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mode for tree entry '%s': %v", entry.Name, err)
+		}
+		id, err := object.ParseObjectID(entry.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hash value '%s' for tree entry '%s'", entry.Hash, entry.Name)
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Mode: mode, Name: entry.Name, ID: id})
+	}
+	tree.Sort()
+
+	return tree.Encode()
+}
+
+// Parse tree object content into tree entries with pkg/object
+func ParseTree(content []byte) ([]TreeEntry, error) {
+	tree, err := object.ParseTree(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []TreeEntry
+	for _, entry := range tree.Entries {
+		entries = append(entries, TreeEntry{Mode: entry.Mode.String(), Name: entry.Name, Hash: entry.ID.String()})
+	}
+
+	return entries, nil
+}
+
+// Read tree object from objects folder and parse its entries
+func ReadTree(treeHash string) ([]TreeEntry, error) {
+	objType, content, err := ReadObject(treeHash)
+	if err != nil {
+		return nil, err
+	}
+	if objType != TreeObject {
+		return nil, fmt.Errorf("object '%s' is a %s, not a tree", treeHash, objType)
+	}
+
+	return ParseTree(content)
+}
+
+// Build tree objects from index entries (one tree object per folder) and return hash value of root tree
+// Unchanged folders produce the same tree hash, so their already stored objects are reused as they are
+func WriteTree(entries []IndexEntry) (string, error) {
+	var treeEntries []TreeEntry
+
+	// Group entries of the same sub folder to build sub tree
+	subFolders := make(map[string][]IndexEntry)
+	var subFolderNames []string
+
+	for _, entry := range entries {
+		if entry.Stage() != 0 {
+			return "", fmt.Errorf("'%s' has unresolved merge conflict", entry.Path)
+		}
+
+		slashIndex := strings.Index(entry.Path, "/")
+		if slashIndex == -1 {
+			treeEntries = append(treeEntries, TreeEntry{Mode: entry.Mode, Name: entry.Path, Hash: entry.Hash})
+			continue
+		}
+
+		folderName := entry.Path[:slashIndex]
+		if _, ok := subFolders[folderName]; !ok {
+			subFolderNames = append(subFolderNames, folderName)
+		}
+		subFolders[folderName] = append(subFolders[folderName], IndexEntry{
+			Mode: entry.Mode,
+			Hash: entry.Hash,
+			Path: entry.Path[slashIndex+1:],
+		})
+	}
+
+	for _, folderName := range subFolderNames {
+		subTreeHash, err := WriteTree(subFolders[folderName])
+		if err != nil {
+			return "", err
+		}
+		treeEntries = append(treeEntries, TreeEntry{Mode: TreeMode, Name: folderName, Hash: subTreeHash})
+	}
+
+	content, err := EncodeTree(treeEntries)
+	if err != nil {
+		return "", err
+	}
+
+	return WriteObject(TreeObject, content)
+}
+
+// Find hash value of file or folder inside tree by its path (e.g "cmd/root.go")
+func LookupTreePath(treeHash string, path string) (string, error) {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return treeHash, nil
+	}
+
+	currentHash := treeHash
+	for _, name := range strings.Split(path, "/") {
+		treeEntries, err := ReadTree(currentHash)
+		if err != nil {
+			return "", fmt.Errorf("path '%s' does not exist", path)
+		}
+
+		found := false
+		for _, treeEntry := range treeEntries {
+			if treeEntry.Name == name {
+				currentHash = treeEntry.Hash
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("path '%s' does not exist", path)
+		}
+	}
+
+	return currentHash, nil
+}
+
+// Read tree object and return every file inside tree (sub trees included) as index entry sorted by path
+func FlattenTree(treeHash string) ([]IndexEntry, error) {
+	var entries []IndexEntry
+
+	err := flattenTreeInto(treeHash, "", &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	SortEntries(entries)
+
+	return entries, nil
+}
+
+// Walk tree recursively and collect file entries with their full path
+func flattenTreeInto(treeHash string, prefix string, entries *[]IndexEntry) error {
+	treeEntries, err := ReadTree(treeHash)
+	if err != nil {
+		return err
+	}
+
+	for _, treeEntry := range treeEntries {
+		path := prefix + treeEntry.Name
+		if treeEntry.IsTree() {
+			err = flattenTreeInto(treeEntry.Hash, path+"/", entries)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		*entries = append(*entries, IndexEntry{
+			Mode: treeEntry.Mode,
+			Hash: treeEntry.Hash,
+			Path: path,
+		})
+	}
+
+	return nil
+}
+
+// Get every file of the commit snapshot as index entries (empty when there is no commit)
+func GetCommitFiles(commitHash string) []IndexEntry {
+	if commitHash == "" {
+		return []IndexEntry{}
+	}
+
+	entries, err := FlattenTree(GetCommitTreeHash(commitHash))
+	if err != nil {
+		log.Fatalf("Error while reading tree of commit '%s': %v", commitHash, err)
+	}
+
+	return entries
+}
@@ -8,138 +8,11 @@ import (
 	"compress/zlib"
 	"crypto/sha1"
 	"encoding/hex"
-	"fmt"
 	"log"
 	"os"
 	"strings"
-	"time"
 )
 
-type IndexEntry struct {
-	Mode string
-	Hash string
-	Path string
-}
-
-func ReadIndexFile() ([]IndexEntry, error) {
-	// Open the index file
-	indexFile, err := os.Open(".git-go/index")
-	if err != nil {
-		// If the file doesn't exist, return an empty list of entries (new repository)
-		if os.IsNotExist(err) {
-			return []IndexEntry{}, nil
-		}
-		log.Fatalln("Error when opening index file:", err)
-	}
-	defer indexFile.Close()
-
-	// Check if the file is empty
-	fileInfo, err := indexFile.Stat()
-	if err != nil {
-		log.Fatalln("Error when getting file info:", err)
-		return nil, err
-	}
-
-	// If the file size is zero, return an empty slice
-	if fileInfo.Size() == 0 {
-		return []IndexEntry{}, nil
-	}
-
-	// Read the entire compressed file content into a buffer
-	var compressedBuf bytes.Buffer
-	_, err = compressedBuf.ReadFrom(indexFile)
-	if err != nil {
-		return nil, err
-	}
-
-	// Decompress the content
-	decompressedContent, err := DecompressContent(&compressedBuf)
-	if err != nil {
-		log.Fatalln("Error when decompressing index file:", err)
-		return nil, err
-	}
-
-	// Split the decompressed content by lines
-	lines := bytes.Split(decompressedContent, []byte("\n"))
-	var entries []IndexEntry
-
-	// Parse each line into IndexEntry
-	for _, line := range lines {
-		// Skip empty lines
-		if len(line) == 0 {
-			continue
-		}
-
-		parts := bytes.Fields(line) // Split by whitespace
-		if len(parts) != 3 {
-			continue // Ensure the line has 3 parts (mode, hash, path)
-		}
-
-		entries = append(entries, IndexEntry{
-			Mode: string(parts[0]),
-			Hash: string(parts[1]),
-			Path: string(parts[2]),
-		})
-	}
-
-	return entries, nil
-}
-
-func WriteIndexFile(entries []IndexEntry) error {
-	// Open the file with read/write/truncate mode
-	indexFile, err := os.OpenFile(".git-go/index", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
-	}
-	defer indexFile.Close()
-
-	// Write entries to the file
-	var uncompressedBuf bytes.Buffer
-	for _, entry := range entries {
-		entryValue := fmt.Sprintf("%s %s %s\n", entry.Mode, entry.Hash, entry.Path)
-		uncompressedBuf.Write([]byte(entryValue))
-	}
-
-	// Compress the uncompressed content
-	var compressBuf bytes.Buffer
-	err = CompressContent(&compressBuf, uncompressedBuf.Bytes())
-	if err != nil {
-		return err
-	}
-
-	// Write compressed content to the file
-	_, err = indexFile.Write(compressBuf.Bytes())
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func UpdateIndexFileHashValue(entries []IndexEntry, filepath string, newHash string) []IndexEntry {
-	var isUpdated bool
-	for i, entry := range entries {
-		if entry.Path == filepath {
-			if entry.Hash != newHash {
-				// Update the hash value
-				entries[i].Hash = newHash
-				isUpdated = true
-			}
-		}
-	}
-	// If index entry is new, them add to entry array slice
-	if !isUpdated {
-		newIndexEntry := IndexEntry{
-			Mode: "100644",
-			Hash: newHash,
-			Path: filepath,
-		}
-		entries = append(entries, newIndexEntry)
-	}
-
-	return entries
-}
-
 func HandFileContent(fileContentBytes []byte) (string, error) {
 	// Get hash value of file content
 	h := sha1.New()
@@ -177,32 +50,41 @@ func DecompressContent(compressedBuf *bytes.Buffer) ([]byte, error) {
 	return decompressBuf.Bytes(), nil
 }
 
-// Get current active branch (e.g main or dev, etc...)
-func GerCurrentBranch() string {
-	var headFileContentBuf bytes.Buffer
-	headFile, err := os.Open(".git-go/HEAD")
-
+// Read HEAD file content without trailing new line (e.g ref: refs/heads/master)
+func ReadHeadContent() string {
+	headFileContent, err := os.ReadFile(GitPath("HEAD"))
 	if os.IsNotExist(err) {
 		log.Fatalln("HEAD file is not exist")
 	}
-
-	// Read head file content
-	_, err = headFileContentBuf.ReadFrom(headFile)
 	if err != nil {
 		log.Fatalln("Error while reading HEAD content")
 	}
 
-	parts := strings.Split(headFileContentBuf.String(), " ")
+	return strings.TrimSpace(string(headFileContent))
+}
+
+// Check HEAD is pointing directly to a commit instead of a branch
+func IsDetachedHead() bool {
+	return !strings.HasPrefix(ReadHeadContent(), "ref:")
+}
+
+// Get current active branch (e.g main or dev, etc...), empty string is returned for detached HEAD
+func GerCurrentBranch() string {
+	headContent := ReadHeadContent()
+	if !strings.HasPrefix(headContent, "ref:") {
+		return ""
+	}
+
+	parts := strings.Split(headContent, " ")
 	if len(parts) != 2 || parts[0] != "ref:" {
 		log.Fatalln("Invalid HEAD content format")
 	}
 
-	// Extract branch name by splitting the ref path by "/"
-	refParts := strings.Split(parts[1], "/")
-	if len(refParts) < 3 {
+	// Extract branch name by removing the "refs/heads/" prefix from ref path
+	if !strings.HasPrefix(parts[1], "refs/heads/") || parts[1] == "refs/heads/" {
 		log.Fatalln("Invalid ref format in HEAD file")
 	}
-	branch := refParts[2] // This is the branch name
+	branch := strings.TrimPrefix(parts[1], "refs/heads/") // This is the branch name
 
 	return branch
 }
@@ -210,59 +92,36 @@ func GerCurrentBranch() string {
 // Get current commit hash
 func GetCurrentCommit() string {
 	currentBranch := GerCurrentBranch()
-	latestCommitFilePath := fmt.Sprintf(".git-go/refs/heads/%s", currentBranch)
-
-	var latestCommitBuf bytes.Buffer
-	latestCommitFile, err := os.Open(latestCommitFilePath)
-	if os.IsNotExist(err) {
-		return ""
+	// Detached HEAD store the commit hash value directly
+	if currentBranch == "" {
+		return ReadHeadContent()
 	}
 
-	latestCommitBuf.ReadFrom(latestCommitFile)
-
-	// Return the hash value of latest commit obj hash value
-	return latestCommitBuf.String()
+	return GetBranchCommit(currentBranch)
 }
 
-// Function to update the latest commit hash value in the branch reference file
-func UpdateCommitHashValue(newHash string) {
-	currentBranch := GerCurrentBranch() // Get the current branch (assumed already implemented)
-	latestCommitFilePath := fmt.Sprintf(".git-go/refs/heads/%s", currentBranch)
-
-	// Open the file for writing, truncate the content but don't recreate it
-	latestCommitFile, err := os.OpenFile(latestCommitFilePath, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0666)
+// Function to update the latest commit hash value in the branch reference file, message is recorded in reflog
+func UpdateCommitHashValue(newHash string, message string) {
+	// Detached HEAD is updated directly instead of branch reference file
+	err := UpdateRef("HEAD", newHash, message)
 	if err != nil {
-		log.Fatalf("Error when opening current branch file: %v", err)
-	}
-	defer latestCommitFile.Close()
-
-	// Write the new hash value to the file
-	_, err = latestCommitFile.Write([]byte(newHash))
-	if err != nil {
-		log.Fatalf("Error writing commit hash to file: %v", err)
+		log.Fatalf("Error writing commit hash to current branch: %v", err)
 	}
 }
 
 // Read commit object
 func ReadCommitObject(hashValue string) []byte {
-	var buf bytes.Buffer
-	// Path to commit object
-	filePath := fmt.Sprintf(".git-go/objects/%s/%s", hashValue[:2], hashValue[2:])
-
-	file, err := os.Open(filePath)
+	objType, content, err := ReadObject(hashValue)
 	if err != nil {
 		log.Fatalln("Error while reading commit object file")
 	}
 
-	// Read file content
-	buf.ReadFrom(file)
-	// Decompress the file content (everything is compress with zlib compression)
-	decompressBuf, err := DecompressContent(&buf)
-	if err != nil {
-		log.Fatalln("Error while decompressing commit object content")
+	// Make sure the object is really a commit object
+	if objType != CommitObject {
+		log.Fatalf("Object '%s' is a %s, not a commit", hashValue, objType)
 	}
 
-	return decompressBuf // Return decompress bytes buffer
+	return content // Return decompressed commit content without object header
 }
 
 // Get hash value of staging file with their file name
@@ -283,23 +142,39 @@ func GetHashValueOfStagedFile(filename string) string {
 
 // Check file is made changes and modified to use in (e.g before adding file to staging area make sure file is modified or not)
 func IsFileModified(filename string) bool {
-	// Read file content
-	fileContentBytes, err := os.ReadFile(filename)
+	entries, err := ReadIndexFile()
+	if err != nil {
+		log.Fatalln("Error while reading index file")
+	}
+
+	entry, ok := EntriesToMap(entries)[filename]
+	if !ok || entry.Stage() != 0 {
+		return true // File is not staged yet or it has merge conflict to resolve
+	}
+
+	fileInfo, err := os.Lstat(filename)
 	if err != nil {
 		log.Fatalf("Cannot read the content of file '%s'", filename)
 	}
 
-	newHashValue, err := HandFileContent(fileContentBytes)
+	isModified, err := IsEntryModified(entry, fileInfo)
 	if err != nil {
 		log.Fatalf("Cannot generate hash value for file '%s'", filename)
 	}
+	return isModified
+}
 
-	oldHashValue := GetHashValueOfStagedFile(filename)
+// Check working tree file of index entry has different content or mode than the staged one
+func IsEntryModified(entry IndexEntry, fileInfo os.FileInfo) (bool, error) {
+	// Cached stat data is same, so file content doesn't need to be hashed again
+	if IsEntryStatClean(entry, fileInfo) {
+		return false, nil
+	}
 
-	return newHashValue != oldHashValue
-}
+	newHashValue, err := HashWorkingFile(entry.Path)
+	if err != nil {
+		return false, err
+	}
 
-// Get the current time in RFC3339 format
-func GetCurrentTime() string {
-	return time.Now().Format(time.RFC3339)
+	return newHashValue != entry.Hash || FileModeOf(fileInfo) != entry.Mode, nil
 }
@@ -0,0 +1,71 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package utils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Read working tree file content as it is stored in blob object, content of symlink is its target path
+func ReadWorkingFile(path string) ([]byte, error) {
+	fileInfo, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fileInfo.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(target), nil
+	}
+	return os.ReadFile(path)
+}
+
+// Get hash value of working tree file as it would be stored as blob object
+func HashWorkingFile(filename string) (string, error) {
+	fileContentBytes, err := ReadWorkingFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	return HashObject(BlobObject, fileContentBytes)
+}
+
+// Walk the working directory and return every file path that is not ignored (sorted and with "/" separator)
+func ListWorkingFiles() ([]string, error) {
+	var files []string
+	ignoreMatcher := NewIgnoreMatcher()
+
+	err := filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == "." {
+			return nil
+		}
+
+		if d.IsDir() {
+			// Never look inside repository folders and ignored folders
+			if IsGitDirPath(path) || d.Name() == ".git" || ignoreMatcher.IsIgnored(filepath.ToSlash(path), true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !ignoreMatcher.IsIgnored(filepath.ToSlash(path), false) {
+			files = append(files, filepath.ToSlash(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
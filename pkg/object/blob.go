@@ -0,0 +1,24 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package object
+
+// File content, blob has no structure so every content is valid
+type Blob struct {
+	Data []byte
+}
+
+// Parse blob content
+func ParseBlob(content []byte) (*Blob, error) {
+	return &Blob{Data: append([]byte(nil), content...)}, nil
+}
+
+// Type of blob object
+func (blob *Blob) Type() Type {
+	return TypeBlob
+}
+
+// Encode blob to object content
+func (blob *Blob) Encode() ([]byte, error) {
+	return append([]byte(nil), blob.Data...), nil
+}
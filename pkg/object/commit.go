@@ -0,0 +1,146 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package object
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Header after committer line that git-go doesn't model (e.g encoding, gpgsig, mergetag)
+// Multi-line value is kept with "\n" between lines, continuation lines are written with leading space
+type Header struct {
+	Key   string
+	Value string
+}
+
+// Commit object, a merge commit has more than one parent and the first commit has none
+type Commit struct {
+	Tree         ObjectID
+	Parents      []ObjectID
+	Author       Signature
+	Committer    Signature
+	ExtraHeaders []Header
+	Message      string // Kept exactly as written, usually ends with new line
+}
+
+// Split object content into header lines and message, headers and message are separated by blank line
+func splitHeaders(content []byte) ([]string, string, error) {
+	headers, message, found := strings.Cut(string(content), "\n\n")
+	if !found {
+		return nil, "", errors.New("missing blank line between headers and message")
+	}
+	return strings.Split(headers, "\n"), message, nil
+}
+
+// Parse commit content, headers must be in Git order: tree, parent, author, committer, then extra headers
+func ParseCommit(content []byte) (*Commit, error) {
+	lines, message, err := splitHeaders(content)
+	if err != nil {
+		return nil, err
+	}
+
+	commit := &Commit{Message: message}
+	// Step of header order, 0 is tree, 1 is parent, 2 is author, 3 is committer, 4 is extra header
+	step := -1
+	for _, line := range lines {
+		if continuation, ok := strings.CutPrefix(line, " "); ok {
+			if step != 4 {
+				return nil, errors.New("continuation line without extra header")
+			}
+			commit.ExtraHeaders[len(commit.ExtraHeaders)-1].Value += "\n" + continuation
+			continue
+		}
+
+		key, value, found := strings.Cut(line, " ")
+		if !found || key == "" {
+			return nil, fmt.Errorf("malformed commit header '%s'", line)
+		}
+
+		switch {
+		case key == "tree" && step == -1:
+			step = 0
+			commit.Tree, err = ParseObjectID(value)
+		case key == "parent" && (step == 0 || step == 1):
+			step = 1
+			var parent ObjectID
+			parent, err = ParseObjectID(value)
+			commit.Parents = append(commit.Parents, parent)
+		case key == "author" && (step == 0 || step == 1):
+			step = 2
+			commit.Author, err = ParseSignature(value)
+		case key == "committer" && step == 2:
+			step = 3
+			commit.Committer, err = ParseSignature(value)
+		case step >= 3 && key != "tree" && key != "parent" && key != "author" && key != "committer":
+			step = 4
+			commit.ExtraHeaders = append(commit.ExtraHeaders, Header{Key: key, Value: value})
+		default:
+			return nil, fmt.Errorf("unexpected commit header '%s'", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s line: %v", key, err)
+		}
+	}
+
+	if step < 3 {
+		return nil, errors.New("commit is missing tree, author or committer")
+	}
+	return commit, nil
+}
+
+// Type of commit object
+func (commit *Commit) Type() Type {
+	return TypeCommit
+}
+
+// Get first line of commit message
+func (commit *Commit) Subject() string {
+	subject, _, _ := strings.Cut(strings.TrimLeft(commit.Message, "\n"), "\n")
+	return subject
+}
+
+// Encode commit to object content, message is written as it is
+func (commit *Commit) Encode() ([]byte, error) {
+	var sb strings.Builder
+
+	sb.WriteString("tree " + commit.Tree.String() + "\n")
+	for _, parent := range commit.Parents {
+		sb.WriteString("parent " + parent.String() + "\n")
+	}
+
+	author, err := commit.Author.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("invalid author: %v", err)
+	}
+	committer, err := commit.Committer.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("invalid committer: %v", err)
+	}
+	sb.WriteString("author " + author + "\n")
+	sb.WriteString("committer " + committer + "\n")
+
+	for _, header := range commit.ExtraHeaders {
+		if err := writeExtraHeader(&sb, header); err != nil {
+			return nil, err
+		}
+	}
+
+	sb.WriteString("\n" + commit.Message)
+	return []byte(sb.String()), nil
+}
+
+// Write extra header, every line after the first one is written with leading space
+func writeExtraHeader(sb *strings.Builder, header Header) error {
+	switch header.Key {
+	case "", "tree", "parent", "author", "committer":
+		return fmt.Errorf("invalid extra header key '%s'", header.Key)
+	}
+	if strings.ContainsAny(header.Key, " \n") {
+		return fmt.Errorf("invalid extra header key '%s'", header.Key)
+	}
+	sb.WriteString(header.Key + " " + strings.ReplaceAll(header.Value, "\n", "\n ") + "\n")
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package object
+
+import (
+	"strings"
+	"testing"
+)
+
+// Signature line used in test objects
+const testSignature = "Kei-K23 <arkar.dev.kei@gmail.com> 1727266964 +0630"
+
+func TestCommitRoundTrip(t *testing.T) {
+	tests := map[string]string{
+		"root commit": "tree " + testTreeID + "\n" +
+			"author " + testSignature + "\n" +
+			"committer " + testSignature + "\n\nInitial commit\n",
+		"merge commit": "tree " + testTreeID + "\n" +
+			"parent " + testParentID + "\n" +
+			"parent " + testTreeID + "\n" +
+			"author " + testSignature + "\n" +
+			"committer Other <other@example.com> 1727270000 -0000\n\nMerge branch 'dev'\n\nDetails\n",
+		"extra and multi-line headers": "tree " + testTreeID + "\n" +
+			"parent " + testParentID + "\n" +
+			"author " + testSignature + "\n" +
+			"committer " + testSignature + "\n" +
+			"encoding ISO-8859-1\n" +
+			"gpgsig -----BEGIN PGP SIGNATURE-----\n \n iQEzBAABCAAdFiEE\n -----END PGP SIGNATURE-----\n\nSigned\n",
+		"message without new line": "tree " + testTreeID + "\n" +
+			"author " + testSignature + "\n" +
+			"committer " + testSignature + "\n\nno new line",
+		"empty message": "tree " + testTreeID + "\n" +
+			"author " + testSignature + "\n" +
+			"committer " + testSignature + "\n\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertRoundTrip(t, TypeCommit, content)
+		})
+	}
+}
+
+func TestParseCommitFields(t *testing.T) {
+	content := "tree " + testTreeID + "\n" +
+		"parent " + testParentID + "\n" +
+		"author " + testSignature + "\n" +
+		"committer Other <other@example.com> 1727270000 -0000\n" +
+		"gpgsig line one\n line two\n\n\nSubject line\n\nBody\n"
+
+	commit, err := ParseCommit([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseCommit failed: %v", err)
+	}
+	if commit.Tree.String() != testTreeID || len(commit.Parents) != 1 || commit.Parents[0].String() != testParentID {
+		t.Errorf("got tree %s and parents %v", commit.Tree, commit.Parents)
+	}
+	if commit.Author.Name != "Kei-K23" || commit.Committer.Email != "other@example.com" {
+		t.Errorf("got author %+v and committer %+v", commit.Author, commit.Committer)
+	}
+	if len(commit.ExtraHeaders) != 1 || commit.ExtraHeaders[0] != (Header{Key: "gpgsig", Value: "line one\nline two"}) {
+		t.Errorf("got extra headers %q", commit.ExtraHeaders)
+	}
+	if commit.Message != "\nSubject line\n\nBody\n" || commit.Subject() != "Subject line" {
+		t.Errorf("got message %q and subject %q", commit.Message, commit.Subject())
+	}
+}
+
+func TestParseCommitErrors(t *testing.T) {
+	tree := "tree " + testTreeID + "\n"
+	parent := "parent " + testParentID + "\n"
+	author := "author " + testSignature + "\n"
+	committer := "committer " + testSignature + "\n"
+
+	tests := map[string]string{
+		"empty":                      "",
+		"missing blank line":         tree + author + committer,
+		"missing tree":               author + committer + "\nmsg\n",
+		"missing author":             tree + committer + "\nmsg\n",
+		"missing committer":          tree + author + "\nmsg\n",
+		"parent before tree":         parent + tree + author + committer + "\nmsg\n",
+		"parent after author":        tree + author + parent + committer + "\nmsg\n",
+		"committer before author":    tree + committer + author + "\nmsg\n",
+		"duplicate tree":             tree + tree + author + committer + "\nmsg\n",
+		"duplicate author":           tree + author + author + committer + "\nmsg\n",
+		"core header after extra":    tree + author + committer + "encoding UTF-8\n" + parent + "\nmsg\n",
+		"extra header before author": tree + "encoding UTF-8\n" + author + committer + "\nmsg\n",
+		"continuation of core line":  tree + " more\n" + author + committer + "\nmsg\n",
+		"header without value":       tree + author + committer + "encoding\n\nmsg\n",
+		"uppercase tree ID":          "tree " + strings.ToUpper(testTreeID) + "\n" + author + committer + "\nmsg\n",
+		"short parent ID":            tree + "parent " + testParentID[:7] + "\n" + author + committer + "\nmsg\n",
+		"legacy date":                tree + "author Kei <k@example.com> 2024-09-24T15:54:09+06:30\n" + committer + "\nmsg\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertParseError(t, TypeCommit, content)
+		})
+	}
+}
+
+func TestCommitEncodeErrors(t *testing.T) {
+	signature, _ := ParseSignature(testSignature)
+	valid := Commit{Author: signature, Committer: signature, Message: "msg\n"}
+
+	badAuthor := valid
+	badAuthor.Author.Name = "Bad <Name>"
+	badHeader := valid
+	badHeader.ExtraHeaders = []Header{{Key: "parent", Value: testParentID}}
+	spaceHeader := valid
+	spaceHeader.ExtraHeaders = []Header{{Key: "bad key", Value: "value"}}
+
+	for name, commit := range map[string]Commit{"author": badAuthor, "core header key": badHeader, "key with space": spaceHeader} {
+		if _, err := commit.Encode(); err == nil {
+			t.Errorf("Encode accepted commit with invalid %s", name)
+		}
+	}
+
+	content, err := valid.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if _, err := ParseCommit(content); err != nil {
+		t.Errorf("ParseCommit rejected encoded commit: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+
+// Package object is the typed model of Git objects (blob, tree, commit and tag).
+// Parse functions are strict, so encoding a parsed object always gives back the exact same bytes.
+package object
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Type of Git object, written in object header (e.g "blob 12\x00")
+type Type string
+
+const (
+	TypeBlob   Type = "blob"
+	TypeTree   Type = "tree"
+	TypeCommit Type = "commit"
+	TypeTag    Type = "tag"
+)
+
+// Parse object type name, unknown names are rejected
+func ParseType(name string) (Type, error) {
+	switch objType := Type(name); objType {
+	case TypeBlob, TypeTree, TypeCommit, TypeTag:
+		return objType, nil
+	}
+	return "", fmt.Errorf("unknown object type '%s'", name)
+}
+
+// Size of SHA-1 object ID in bytes
+const IDSize = sha1.Size
+
+// SHA-1 hash of encoded object that identifies it
+type ObjectID [IDSize]byte
+
+// ID with every byte zero, used where object doesn't exist (e.g reflog of created ref)
+var ZeroID ObjectID
+
+// Parse full 40 character lowercase hexadecimal object ID
+func ParseObjectID(value string) (ObjectID, error) {
+	var id ObjectID
+	if len(value) != hex.EncodedLen(IDSize) {
+		return id, fmt.Errorf("invalid object ID '%s'", value)
+	}
+	for _, char := range value {
+		if (char < '0' || char > '9') && (char < 'a' || char > 'f') {
+			return id, fmt.Errorf("invalid object ID '%s'", value)
+		}
+	}
+	hex.Decode(id[:], []byte(value))
+	return id, nil
+}
+
+// Format object ID as 40 character hexadecimal string
+func (id ObjectID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// Check object ID is the zero ID
+func (id ObjectID) IsZero() bool {
+	return id == ZeroID
+}
+
+// Compute ID of object content, the hash covers object header and content (e.g "blob 12\x00hello world\n")
+func ComputeID(objType Type, content []byte) ObjectID {
+	hasher := sha1.New()
+	hasher.Write([]byte(string(objType) + " " + strconv.Itoa(len(content)) + "\x00"))
+	hasher.Write(content)
+
+	var id ObjectID
+	copy(id[:], hasher.Sum(nil))
+	return id
+}
+
+// Any Git object, Encode gives object content without header
+type Object interface {
+	Type() Type
+	Encode() ([]byte, error)
+}
+
+// Compute ID of object from its encoded content
+func ID(obj Object) (ObjectID, error) {
+	content, err := obj.Encode()
+	if err != nil {
+		return ZeroID, err
+	}
+	return ComputeID(obj.Type(), content), nil
+}
+
+// Parse object content of the given type into its typed object
+func Parse(objType Type, content []byte) (Object, error) {
+	switch objType {
+	case TypeBlob:
+		return ParseBlob(content)
+	case TypeTree:
+		return ParseTree(content)
+	case TypeCommit:
+		return ParseCommit(content)
+	case TypeTag:
+		return ParseTag(content)
+	}
+	return nil, errors.New("unknown object type '" + string(objType) + "'")
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package object
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Hexadecimal object IDs used in test objects
+const (
+	testTreeID   = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+	testParentID = "3b18e512dba79e4c8300dd08aeb37f8e728b8dad"
+)
+
+// Parse content and check encoding it gives back exactly the same bytes
+func assertRoundTrip(t *testing.T, objType Type, content string) Object {
+	t.Helper()
+
+	obj, err := Parse(objType, []byte(content))
+	if err != nil {
+		t.Fatalf("Parse(%s) failed: %v\ncontent:\n%q", objType, err, content)
+	}
+	encoded, err := obj.Encode()
+	if err != nil {
+		t.Fatalf("Encode(%s) failed: %v", objType, err)
+	}
+	if !bytes.Equal(encoded, []byte(content)) {
+		t.Fatalf("round-trip of %s changed content\nwant %q\ngot  %q", objType, content, encoded)
+	}
+	return obj
+}
+
+// Check parsing content fails
+func assertParseError(t *testing.T, objType Type, content string) {
+	t.Helper()
+
+	if _, err := Parse(objType, []byte(content)); err == nil {
+		t.Errorf("Parse(%s) accepted malformed content %q", objType, content)
+	}
+}
+
+func TestParseObjectID(t *testing.T) {
+	id, err := ParseObjectID(testParentID)
+	if err != nil {
+		t.Fatalf("ParseObjectID failed: %v", err)
+	}
+	if id.String() != testParentID {
+		t.Errorf("String() = %s, want %s", id, testParentID)
+	}
+	if id.IsZero() || !ZeroID.IsZero() {
+		t.Errorf("IsZero is wrong for %s or zero ID", id)
+	}
+
+	for _, value := range []string{
+		"",
+		testParentID[:39],
+		testParentID + "0",
+		strings.ToUpper(testParentID),
+		"g" + testParentID[1:],
+	} {
+		if _, err := ParseObjectID(value); err == nil {
+			t.Errorf("ParseObjectID accepted '%s'", value)
+		}
+	}
+}
+
+func TestComputeID(t *testing.T) {
+	tests := []struct {
+		objType Type
+		content string
+		want    string
+	}{
+		{TypeBlob, "hello world\n", testParentID},
+		{TypeTree, "", testTreeID},
+		{TypeBlob, "", "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"},
+	}
+	for _, test := range tests {
+		if got := ComputeID(test.objType, []byte(test.content)).String(); got != test.want {
+			t.Errorf("ComputeID(%s, %q) = %s, want %s", test.objType, test.content, got, test.want)
+		}
+	}
+}
+
+func TestParseType(t *testing.T) {
+	for _, name := range []string{"blob", "tree", "commit", "tag"} {
+		if _, err := ParseType(name); err != nil {
+			t.Errorf("ParseType rejected '%s': %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "Blob", "ofs-delta"} {
+		if _, err := ParseType(name); err == nil {
+			t.Errorf("ParseType accepted '%s'", name)
+		}
+	}
+	if _, err := Parse(Type("delta"), nil); err == nil {
+		t.Error("Parse accepted unknown object type")
+	}
+}
+
+func TestBlobRoundTrip(t *testing.T) {
+	for _, content := range []string{"", "hello world\n", "no new line", "\x00binary\xff\n"} {
+		blob := assertRoundTrip(t, TypeBlob, content).(*Blob)
+		if string(blob.Data) != content {
+			t.Errorf("blob data = %q, want %q", blob.Data, content)
+		}
+	}
+
+	id, err := ID(&Blob{Data: []byte("hello world\n")})
+	if err != nil || id.String() != testParentID {
+		t.Errorf("ID(blob) = %s, %v, want %s", id, err, testParentID)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package object
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Identity with timestamp stored in author, committer and tagger lines (e.g "Name <email> 1727266964 +0630")
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// Parse signature in Git format, timezone is kept as written (e.g "-0000" stays "-0000" when encoded)
+func ParseSignature(value string) (Signature, error) {
+	emailStart := strings.IndexByte(value, '<')
+	emailEnd := strings.IndexByte(value, '>')
+	if emailStart == -1 || emailEnd < emailStart {
+		return Signature{}, fmt.Errorf("signature '%s' is missing email", value)
+	}
+	if emailStart > 0 && value[emailStart-1] != ' ' || emailStart == 0 {
+		return Signature{}, fmt.Errorf("signature '%s' must have space between name and email", value)
+	}
+
+	signature := Signature{Name: value[:emailStart-1], Email: value[emailStart+1 : emailEnd]}
+
+	dateValue, ok := strings.CutPrefix(value[emailEnd+1:], " ")
+	parts := strings.Split(dateValue, " ")
+	if !ok || len(parts) != 2 {
+		return Signature{}, fmt.Errorf("signature '%s' has invalid date", value)
+	}
+
+	timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || timestamp < 0 || strconv.FormatInt(timestamp, 10) != parts[0] {
+		return Signature{}, fmt.Errorf("signature '%s' has invalid timestamp", value)
+	}
+
+	zone := parts[1]
+	if len(zone) != 5 || (zone[0] != '+' && zone[0] != '-') {
+		return Signature{}, fmt.Errorf("signature '%s' has invalid timezone", value)
+	}
+	hours, hoursErr := strconv.ParseUint(zone[1:3], 10, 8)
+	minutes, minutesErr := strconv.ParseUint(zone[3:5], 10, 8)
+	if hoursErr != nil || minutesErr != nil || minutes >= 60 {
+		return Signature{}, fmt.Errorf("signature '%s' has invalid timezone", value)
+	}
+	offset := int(hours)*3600 + int(minutes)*60
+	if zone[0] == '-' {
+		offset = -offset
+	}
+
+	// Location name keeps timezone text, so it is encoded back exactly as written
+	signature.When = time.Unix(timestamp, 0).In(time.FixedZone(zone, offset))
+	return signature, nil
+}
+
+// Format timezone of signature time as +hhmm or -hhmm
+func formatZone(when time.Time) string {
+	if name := when.Location().String(); len(name) == 5 && (name[0] == '+' || name[0] == '-') {
+		if _, offset := when.Zone(); name == when.Format("-0700") || (offset == 0 && name == "-0000") {
+			return name
+		}
+	}
+	return when.Format("-0700")
+}
+
+// Encode signature in Git format, name and email must not break the line format
+func (signature Signature) Encode() (string, error) {
+	if strings.ContainsAny(signature.Name, "<>\n") || strings.ContainsAny(signature.Email, "<>\n") {
+		return "", fmt.Errorf("signature name and email must not contain '<', '>' or new line")
+	}
+	return fmt.Sprintf("%s <%s> %d %s", signature.Name, signature.Email, signature.When.Unix(), formatZone(signature.When)), nil
+}
+
+// Format signature in Git format (invalid characters are not checked, use Encode for that)
+func (signature Signature) String() string {
+	return fmt.Sprintf("%s <%s> %d %s", signature.Name, signature.Email, signature.When.Unix(), formatZone(signature.When))
+}
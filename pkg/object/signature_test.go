@@ -0,0 +1,88 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package object
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignatureRoundTrip(t *testing.T) {
+	for _, value := range []string{
+		"Kei-K23 <arkar.dev.kei@gmail.com> 1727266964 +0630",
+		"A U Thor <author@example.com> 0 +0000",
+		"Negative Zero <nz@example.com> 1700000000 -0000",
+		"West <w@example.com> 1700000000 -0800",
+		" <> 1 +0000",
+	} {
+		signature, err := ParseSignature(value)
+		if err != nil {
+			t.Errorf("ParseSignature('%s') failed: %v", value, err)
+			continue
+		}
+		encoded, err := signature.Encode()
+		if err != nil || encoded != value {
+			t.Errorf("Encode() = '%s', %v, want '%s'", encoded, err, value)
+		}
+		if signature.String() != value {
+			t.Errorf("String() = '%s', want '%s'", signature.String(), value)
+		}
+	}
+}
+
+func TestParseSignatureFields(t *testing.T) {
+	signature, err := ParseSignature("Kei K23 <kei@example.com> 1727266964 +0630")
+	if err != nil {
+		t.Fatalf("ParseSignature failed: %v", err)
+	}
+	if signature.Name != "Kei K23" || signature.Email != "kei@example.com" {
+		t.Errorf("got name '%s' and email '%s'", signature.Name, signature.Email)
+	}
+	if _, offset := signature.When.Zone(); signature.When.Unix() != 1727266964 || offset != 6*3600+30*60 {
+		t.Errorf("got time %v", signature.When)
+	}
+}
+
+func TestParseSignatureErrors(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"No Email 1 +0000",
+		"Name<name@example.com> 1 +0000",
+		"Name <name@example.com>",
+		"Name <name@example.com>1 +0000",
+		"Name <name@example.com> 1",
+		"Name <name@example.com> 1 +0000 extra",
+		"Name <name@example.com>  1 +0000",
+		"Name <name@example.com> -1 +0000",
+		"Name <name@example.com> +1 +0000",
+		"Name <name@example.com> 01 +0000",
+		"Name <name@example.com> 1 0000",
+		"Name <name@example.com> 1 +000",
+		"Name <name@example.com> 1 +0a00",
+		"Name <name@example.com> 1 +0060",
+		"Name <name@example.com> 2024-09-24T15:54:09+06:30",
+	} {
+		if _, err := ParseSignature(value); err == nil {
+			t.Errorf("ParseSignature accepted '%s'", value)
+		}
+	}
+}
+
+func TestSignatureEncodeErrors(t *testing.T) {
+	when := time.Unix(1, 0).UTC()
+	for _, signature := range []Signature{
+		{Name: "Bad <Name>", Email: "a@example.com", When: when},
+		{Name: "Multi\nLine", Email: "a@example.com", When: when},
+		{Name: "Name", Email: "a>b@example.com", When: when},
+	} {
+		if _, err := signature.Encode(); err == nil {
+			t.Errorf("Encode accepted %+v", signature)
+		}
+	}
+
+	encoded, err := Signature{Name: "UTC", Email: "utc@example.com", When: when}.Encode()
+	if err != nil || encoded != "UTC <utc@example.com> 1 +0000" {
+		t.Errorf("Encode() = '%s', %v", encoded, err)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package object
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Annotated tag object, tagger is nil for old tags written without it
+type Tag struct {
+	Object     ObjectID
+	TargetType Type // Type of tagged object
+	Name       string
+	Tagger     *Signature
+	Message    string // Kept exactly as written, signature of signed tag is part of message
+}
+
+// Parse tag content, headers must be object, type, tag and optional tagger in this order
+func ParseTag(content []byte) (*Tag, error) {
+	lines, message, err := splitHeaders(content)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := &Tag{Message: message}
+	keys := []string{"object", "type", "tag", "tagger"}
+	if len(lines) != len(keys) && len(lines) != len(keys)-1 {
+		return nil, errors.New("tag must have object, type, tag and optional tagger headers")
+	}
+
+	for i, line := range lines {
+		key, value, found := strings.Cut(line, " ")
+		if !found || key != keys[i] {
+			return nil, fmt.Errorf("unexpected tag header '%s', expected '%s'", key, keys[i])
+		}
+
+		switch key {
+		case "object":
+			tag.Object, err = ParseObjectID(value)
+		case "type":
+			tag.TargetType, err = ParseType(value)
+		case "tag":
+			if value == "" {
+				err = errors.New("tag name is empty")
+			}
+			tag.Name = value
+		case "tagger":
+			var tagger Signature
+			tagger, err = ParseSignature(value)
+			tag.Tagger = &tagger
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s line: %v", key, err)
+		}
+	}
+
+	return tag, nil
+}
+
+// Type of tag object
+func (tag *Tag) Type() Type {
+	return TypeTag
+}
+
+// Encode tag to object content, message is written as it is
+func (tag *Tag) Encode() ([]byte, error) {
+	if _, err := ParseType(string(tag.TargetType)); err != nil {
+		return nil, err
+	}
+	if tag.Name == "" || strings.ContainsAny(tag.Name, "\n") {
+		return nil, fmt.Errorf("invalid tag name '%s'", tag.Name)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("object " + tag.Object.String() + "\n")
+	sb.WriteString("type " + string(tag.TargetType) + "\n")
+	sb.WriteString("tag " + tag.Name + "\n")
+	if tag.Tagger != nil {
+		tagger, err := tag.Tagger.Encode()
+		if err != nil {
+			return nil, fmt.Errorf("invalid tagger: %v", err)
+		}
+		sb.WriteString("tagger " + tagger + "\n")
+	}
+
+	sb.WriteString("\n" + tag.Message)
+	return []byte(sb.String()), nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package object
+
+import "testing"
+
+func TestTagRoundTrip(t *testing.T) {
+	tests := map[string]string{
+		"with tagger":    "object " + testParentID + "\ntype commit\ntag v1.0.0\ntagger " + testSignature + "\n\nRelease v1.0.0\n",
+		"without tagger": "object " + testParentID + "\ntype commit\ntag v0.1\n\nOld tag\n",
+		"signed tag": "object " + testTreeID + "\ntype tree\ntag signed\ntagger Other <other@example.com> 1 -0000\n\n" +
+			"Signed\n-----BEGIN PGP SIGNATURE-----\n\niQEzBAABCAAdFiEE\n-----END PGP SIGNATURE-----\n",
+		"empty message": "object " + testParentID + "\ntype blob\ntag empty\ntagger " + testSignature + "\n\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertRoundTrip(t, TypeTag, content)
+		})
+	}
+}
+
+func TestParseTagFields(t *testing.T) {
+	tag, err := ParseTag([]byte("object " + testParentID + "\ntype commit\ntag v1.0.0\ntagger " + testSignature + "\n\nRelease\n"))
+	if err != nil {
+		t.Fatalf("ParseTag failed: %v", err)
+	}
+	if tag.Object.String() != testParentID || tag.TargetType != TypeCommit || tag.Name != "v1.0.0" || tag.Message != "Release\n" {
+		t.Errorf("got tag %+v", tag)
+	}
+	if tag.Tagger == nil || tag.Tagger.Name != "Kei-K23" {
+		t.Errorf("got tagger %+v", tag.Tagger)
+	}
+
+	tag, err = ParseTag([]byte("object " + testParentID + "\ntype commit\ntag old\n\nmsg\n"))
+	if err != nil || tag.Tagger != nil {
+		t.Errorf("tag without tagger = %+v, %v", tag, err)
+	}
+}
+
+func TestParseTagErrors(t *testing.T) {
+	object := "object " + testParentID + "\n"
+	tagger := "tagger " + testSignature + "\n"
+
+	tests := map[string]string{
+		"missing blank line":   object + "type commit\ntag v1\n" + tagger,
+		"missing object":       "type commit\ntag v1\n" + tagger + "\nmsg\n",
+		"missing type":         object + "tag v1\n" + tagger + "\nmsg\n",
+		"missing name":         object + "type commit\n" + tagger + "\nmsg\n",
+		"wrong order":          "type commit\n" + object + "tag v1\n" + tagger + "\nmsg\n",
+		"unknown type":         object + "type delta\ntag v1\n" + tagger + "\nmsg\n",
+		"empty name":           object + "type commit\ntag \n" + tagger + "\nmsg\n",
+		"unknown header":       object + "type commit\ntag v1\n" + tagger + "encoding UTF-8\n\nmsg\n",
+		"unknown header first": object + "type commit\ntag v1\nencoding UTF-8\n\nmsg\n",
+		"invalid tagger":       object + "type commit\ntag v1\ntagger nobody\n\nmsg\n",
+		"bad object ID":        "object xyz\ntype commit\ntag v1\n" + tagger + "\nmsg\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertParseError(t, TypeTag, content)
+		})
+	}
+}
+
+func TestTagEncodeErrors(t *testing.T) {
+	for name, tag := range map[string]Tag{
+		"empty name":      {TargetType: TypeCommit, Name: ""},
+		"multi-line name": {TargetType: TypeCommit, Name: "a\nb"},
+		"unknown type":    {TargetType: Type("delta"), Name: "v1"},
+		"invalid tagger":  {TargetType: TypeCommit, Name: "v1", Tagger: &Signature{Name: "<bad>"}},
+	} {
+		if _, err := tag.Encode(); err == nil {
+			t.Errorf("Encode accepted tag with %s", name)
+		}
+	}
+}
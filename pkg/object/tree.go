@@ -0,0 +1,157 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package object
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Mode of tree entry, written as octal number without leading zero (e.g "40000" for folder)
+type FileMode uint32
+
+const (
+	ModeTree       FileMode = 0o40000
+	ModeRegular    FileMode = 0o100644
+	ModeExecutable FileMode = 0o100755
+	ModeSymlink    FileMode = 0o120000
+	ModeSubmodule  FileMode = 0o160000
+)
+
+// Parse tree entry mode, only modes Git writes are accepted
+func ParseFileMode(value string) (FileMode, error) {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || strings.HasPrefix(value, "0") || !FileMode(mode).IsValid() {
+		return 0, fmt.Errorf("invalid file mode '%s'", value)
+	}
+	return FileMode(mode), nil
+}
+
+// Check mode is one of the modes Git writes
+func (mode FileMode) IsValid() bool {
+	switch mode {
+	case ModeTree, ModeRegular, ModeExecutable, ModeSymlink, ModeSubmodule:
+		return true
+	}
+	return false
+}
+
+// Format mode as octal number
+func (mode FileMode) String() string {
+	return strconv.FormatUint(uint64(mode), 8)
+}
+
+// One file, folder or submodule in tree
+type TreeEntry struct {
+	Mode FileMode
+	Name string
+	ID   ObjectID
+}
+
+// Key used to sort tree entries, Git sorts folder as if its name ends with "/"
+func (entry TreeEntry) sortKey() string {
+	if entry.Mode == ModeTree {
+		return entry.Name + "/"
+	}
+	return entry.Name
+}
+
+// Folder listing, entries are kept in Git order
+type Tree struct {
+	Entries []TreeEntry
+}
+
+// Check entry name can be stored in tree
+func validEntryName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\x00")
+}
+
+// Parse tree content, entries must be valid, sorted and unique
+func ParseTree(content []byte) (*Tree, error) {
+	tree := &Tree{}
+	names := make(map[string]bool)
+	for len(content) > 0 {
+		spaceIndex := bytes.IndexByte(content, ' ')
+		if spaceIndex == -1 {
+			return nil, errors.New("tree entry is missing mode")
+		}
+		mode, err := ParseFileMode(string(content[:spaceIndex]))
+		if err != nil {
+			return nil, err
+		}
+		content = content[spaceIndex+1:]
+
+		nullIndex := bytes.IndexByte(content, 0)
+		if nullIndex == -1 {
+			return nil, errors.New("tree entry is missing name terminator")
+		}
+		name := string(content[:nullIndex])
+		if !validEntryName(name) {
+			return nil, fmt.Errorf("invalid tree entry name '%s'", name)
+		}
+		content = content[nullIndex+1:]
+
+		if len(content) < IDSize {
+			return nil, fmt.Errorf("tree entry '%s' has truncated object ID", name)
+		}
+		entry := TreeEntry{Mode: mode, Name: name}
+		copy(entry.ID[:], content[:IDSize])
+		content = content[IDSize:]
+
+		if err := checkEntryOrder(tree.Entries, entry, names); err != nil {
+			return nil, err
+		}
+		tree.Entries = append(tree.Entries, entry)
+	}
+	return tree, nil
+}
+
+// Check entry can follow previous entries, same name may be apart in Git order (e.g blob "a", blob "a.b", tree "a")
+func checkEntryOrder(previous []TreeEntry, entry TreeEntry, names map[string]bool) error {
+	if names[entry.Name] {
+		return fmt.Errorf("duplicate tree entry '%s'", entry.Name)
+	}
+	if count := len(previous); count > 0 && previous[count-1].sortKey() > entry.sortKey() {
+		return fmt.Errorf("tree entry '%s' is not sorted", entry.Name)
+	}
+	names[entry.Name] = true
+	return nil
+}
+
+// Type of tree object
+func (tree *Tree) Type() Type {
+	return TypeTree
+}
+
+// Sort entries in Git order
+func (tree *Tree) Sort() {
+	sort.SliceStable(tree.Entries, func(i, j int) bool {
+		return tree.Entries[i].sortKey() < tree.Entries[j].sortKey()
+	})
+}
+
+// Encode tree to object content, entries must already be sorted (call Sort first)
+func (tree *Tree) Encode() ([]byte, error) {
+	var buffer bytes.Buffer
+	names := make(map[string]bool)
+	for i, entry := range tree.Entries {
+		if !entry.Mode.IsValid() {
+			return nil, fmt.Errorf("tree entry '%s' has invalid file mode %o", entry.Name, uint32(entry.Mode))
+		}
+		if !validEntryName(entry.Name) {
+			return nil, fmt.Errorf("invalid tree entry name '%s'", entry.Name)
+		}
+		if err := checkEntryOrder(tree.Entries[:i], entry, names); err != nil {
+			return nil, err
+		}
+
+		buffer.WriteString(entry.Mode.String() + " " + entry.Name + "\x00")
+		buffer.Write(entry.ID[:])
+	}
+	return buffer.Bytes(), nil
+}
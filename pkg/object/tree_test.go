@@ -0,0 +1,95 @@
+/*
+Copyright © 2024 Kei-K23 <arkar.dev.kei@gmail.com>
+*/
+package object
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Build tree entry content in Git format from mode, name and hexadecimal object ID
+func treeEntry(mode string, name string, id string) string {
+	raw, _ := hex.DecodeString(id)
+	return mode + " " + name + "\x00" + string(raw)
+}
+
+func TestTreeRoundTrip(t *testing.T) {
+	content := treeEntry("100644", "README.md", testParentID) +
+		treeEntry("120000", "link", testParentID) +
+		treeEntry("100755", "run.sh", testParentID) +
+		treeEntry("160000", "sub", testParentID) +
+		treeEntry("100644", "utils.go", testParentID) +
+		treeEntry("100644", "utils.go.orig", testParentID) +
+		treeEntry("40000", "utils", testTreeID)
+
+	tree := assertRoundTrip(t, TypeTree, content).(*Tree)
+	if len(tree.Entries) != 7 {
+		t.Fatalf("got %d entries, want 7", len(tree.Entries))
+	}
+	last := tree.Entries[6]
+	if last.Mode != ModeTree || last.Name != "utils" || last.ID.String() != testTreeID {
+		t.Errorf("last entry = %+v", last)
+	}
+
+	assertRoundTrip(t, TypeTree, "")
+}
+
+func TestParseTreeErrors(t *testing.T) {
+	tests := map[string]string{
+		"mode with leading zero":  treeEntry("040000", "dir", testTreeID),
+		"unknown mode":            treeEntry("100664", "file", testParentID),
+		"mode is not octal":       treeEntry("100684", "file", testParentID),
+		"empty name":              treeEntry("100644", "", testParentID),
+		"dot name":                treeEntry("40000", ".", testTreeID),
+		"dot dot name":            treeEntry("40000", "..", testTreeID),
+		"name with slash":         treeEntry("100644", "a/b", testParentID),
+		"missing mode":            "file\x00",
+		"missing name terminator": "100644 file",
+		"truncated object ID":     treeEntry("100644", "file", testParentID)[:20],
+		"unsorted entries":        treeEntry("100644", "b", testParentID) + treeEntry("100644", "a", testParentID),
+		"folder sorted as file":   treeEntry("40000", "a", testTreeID) + treeEntry("100644", "a.b", testParentID),
+		"adjacent duplicate":      treeEntry("100644", "a", testParentID) + treeEntry("100644", "a", testParentID),
+		"apart duplicate":         treeEntry("100644", "a", testParentID) + treeEntry("100644", "a.b", testParentID) + treeEntry("40000", "a", testTreeID),
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertParseError(t, TypeTree, content)
+		})
+	}
+}
+
+func TestTreeEncode(t *testing.T) {
+	id, _ := ParseObjectID(testParentID)
+	treeID, _ := ParseObjectID(testTreeID)
+
+	// Sort puts folder "a" after "a.b" because it is compared as "a/"
+	tree := &Tree{Entries: []TreeEntry{
+		{Mode: ModeTree, Name: "a", ID: treeID},
+		{Mode: ModeRegular, Name: "a.b", ID: id},
+		{Mode: ModeRegular, Name: "B", ID: id},
+	}}
+	if _, err := tree.Encode(); err == nil {
+		t.Error("Encode accepted unsorted entries")
+	}
+	tree.Sort()
+	content, err := tree.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	want := treeEntry("100644", "B", testParentID) + treeEntry("100644", "a.b", testParentID) + treeEntry("40000", "a", testTreeID)
+	if string(content) != want {
+		t.Errorf("Encode() = %q, want %q", content, want)
+	}
+
+	invalid := map[string][]TreeEntry{
+		"apart duplicate": {{Mode: ModeRegular, Name: "a", ID: id}, {Mode: ModeRegular, Name: "a.b", ID: id}, {Mode: ModeTree, Name: "a", ID: treeID}},
+		"invalid mode":    {{Mode: 0o100600, Name: "a", ID: id}},
+		"invalid name":    {{Mode: ModeRegular, Name: "a/b", ID: id}},
+	}
+	for name, entries := range invalid {
+		if _, err := (&Tree{Entries: entries}).Encode(); err == nil {
+			t.Errorf("Encode accepted tree with %s", name)
+		}
+	}
+}